@@ -1,24 +1,48 @@
 package feedforward
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // Represents a neural activation function.
+// Name identifies the activation function when a network is serialized, custom activation functions must be given a
+// unique name in order to be serialized.
 type ActivationFunction struct {
+	Name     string
 	Value    func(net float64) float64
 	Gradient func(net float64) float64
 }
 
+// Returns the built-in activation function with the given name.
+func ActivationByName(name string) (ActivationFunction, error) {
+	switch name {
+	case "sigmoid":
+		return Sigmoid(), nil
+	case "tanh":
+		return TanH(), nil
+	case "relu":
+		return ReLu(), nil
+	case "linear":
+		return Linear(), nil
+	default:
+		return ActivationFunction{}, fmt.Errorf("unknown activation function %q", name)
+	}
+}
+
 // Helper function to fill a slice of size n with the given ActivationFunction.
-func Repeat(activation ActivationFunction, n int) {
+func Repeat(activation ActivationFunction, n int) []ActivationFunction {
 	activations := make([]ActivationFunction, n)
 	for i := 0; i < n; i++ {
 		activations[i] = activation
 	}
+	return activations
 }
 
 // Sigmoid activation function.
 func Sigmoid() ActivationFunction {
 	return ActivationFunction{
+		Name:     "sigmoid",
 		Value:    func(net float64) float64 { return 1 / (1 + math.Exp(-net)) },
 		Gradient: func(net float64) float64 { return net * (1 - net) },
 	}
@@ -27,6 +51,7 @@ func Sigmoid() ActivationFunction {
 // TanH activation function.
 func TanH() ActivationFunction {
 	return ActivationFunction{
+		Name:     "tanh",
 		Value:    func(net float64) float64 { return (1 - math.Exp(-2*net)) / (1 + math.Exp(-2*net)) },
 		Gradient: func(net float64) float64 { return 1 - math.Pow(net, 2) },
 	}
@@ -35,6 +60,7 @@ func TanH() ActivationFunction {
 // ReLu activation function.
 func ReLu() ActivationFunction {
 	return ActivationFunction{
+		Name:  "relu",
 		Value: func(net float64) float64 { return math.Max(net, 0) },
 		Gradient: func(net float64) float64 {
 			if net > 0 {
@@ -44,3 +70,12 @@ func ReLu() ActivationFunction {
 		},
 	}
 }
+
+// Linear (identity) activation function, used for example by the output layer of regression networks.
+func Linear() ActivationFunction {
+	return ActivationFunction{
+		Name:     "linear",
+		Value:    func(net float64) float64 { return net },
+		Gradient: func(net float64) float64 { return 1 },
+	}
+}
@@ -0,0 +1,363 @@
+package feedforward
+
+import (
+	"math"
+	"sort"
+)
+
+// Classification metrics interpret outputs in one of two ways:
+// a single output is treated as the probability of the positive class of a binary problem and is thresholded at 0.5,
+// multiple outputs are treated as one-hot encoded classes, where the predicted class is the one with the largest output.
+
+// Represents the strategy used for averaging per-class metrics.
+type Average int
+
+const (
+	// Unweighted mean of the per-class metrics.
+	Macro Average = iota
+	// Metric computed from the total true positives, false positives and false negatives of all classes.
+	Micro
+	// Mean of the per-class metrics weighted by the number of samples of each class.
+	Weighted
+	// Metric of the positive class (class 1) only, intended for binary problems.
+	Binary
+)
+
+// Returns the number of classes represented by the given output.
+func classCount(output []float64) int {
+	if len(output) == 1 {
+		return 2
+	}
+	return len(output)
+}
+
+// Returns the class represented by the given output.
+func classOf(output []float64) int {
+	if len(output) == 1 {
+		if output[0] >= 0.5 {
+			return 1
+		}
+		return 0
+	}
+	return argmax(output)
+}
+
+// Returns the index of the largest value of the given slice.
+func argmax(values []float64) int {
+	index := 0
+	for i := 1; i < len(values); i++ {
+		if values[i] > values[index] {
+			index = i
+		}
+	}
+	return index
+}
+
+// Returns the probability of the given class for the given output.
+func classProbability(output []float64, class int) float64 {
+	if len(output) == 1 {
+		if class == 1 {
+			return output[0]
+		}
+		return 1 - output[0]
+	}
+	return output[class]
+}
+
+// Computes the confusion matrix of the predictor on the given samples.
+// Rows of the matrix correspond to the actual class and columns to the predicted class.
+func ConfusionMatrix(predictor Predictor, samples []Sample) [][]int {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	classes := classCount(samples[0].Output)
+	matrix := make([][]int, classes)
+	for i := range matrix {
+		matrix[i] = make([]int, classes)
+	}
+
+	for _, sample := range samples {
+		matrix[classOf(sample.Output)][classOf(predictor(sample.Input))]++
+	}
+	return matrix
+}
+
+// Accuracy metric, the fraction of correctly classified samples (higher is better).
+func Accuracy(predictor Predictor, samples []Sample) float64 {
+	correct := 0
+	for _, sample := range samples {
+		if classOf(sample.Output) == classOf(predictor(sample.Input)) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(samples))
+}
+
+// Balanced accuracy metric, the mean recall of all classes present in the samples (higher is better).
+func BalancedAccuracy(predictor Predictor, samples []Sample) float64 {
+	matrix := ConfusionMatrix(predictor, samples)
+	sum, classes := 0., 0
+	for i := range matrix {
+		support := 0
+		for _, count := range matrix[i] {
+			support += count
+		}
+		if support == 0 {
+			continue
+		}
+		sum += float64(matrix[i][i]) / float64(support)
+		classes++
+	}
+	return sum / float64(classes)
+}
+
+// Returns a metric which computes the precision averaged by the given strategy (higher is better).
+func PrecisionScore(average Average) Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		return averageClasses(ConfusionMatrix(predictor, samples), average, precision)
+	}
+}
+
+// Returns a metric which computes the recall averaged by the given strategy (higher is better).
+func RecallScore(average Average) Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		return averageClasses(ConfusionMatrix(predictor, samples), average, recall)
+	}
+}
+
+// Returns a metric which computes the F1 score averaged by the given strategy (higher is better).
+func F1Score(average Average) Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		return averageClasses(ConfusionMatrix(predictor, samples), average, f1)
+	}
+}
+
+// Computes precision from true positives, false positives and false negatives, 0 if undefined.
+func precision(tp, fp, fn int) float64 {
+	return ratio(tp, tp+fp)
+}
+
+// Computes recall from true positives, false positives and false negatives, 0 if undefined.
+func recall(tp, fp, fn int) float64 {
+	return ratio(tp, tp+fn)
+}
+
+// Computes the F1 score from true positives, false positives and false negatives, 0 if undefined.
+func f1(tp, fp, fn int) float64 {
+	return ratio(2*tp, 2*tp+fp+fn)
+}
+
+// Divides two counts, returning 0 if the denominator is 0.
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// Averages a per-class metric computed from the given confusion matrix using the given strategy.
+func averageClasses(matrix [][]int, average Average, metric func(tp, fp, fn int) float64) float64 {
+	classes := len(matrix)
+	tp, fp, fn, support := make([]int, classes), make([]int, classes), make([]int, classes), make([]int, classes)
+	total := 0
+	for i := 0; i < classes; i++ {
+		for j := 0; j < classes; j++ {
+			count := matrix[i][j]
+			support[i] += count
+			total += count
+			if i == j {
+				tp[i] += count
+			} else {
+				fn[i] += count
+				fp[j] += count
+			}
+		}
+	}
+
+	switch average {
+	case Micro:
+		sumTp, sumFp, sumFn := 0, 0, 0
+		for i := 0; i < classes; i++ {
+			sumTp += tp[i]
+			sumFp += fp[i]
+			sumFn += fn[i]
+		}
+		return metric(sumTp, sumFp, sumFn)
+	case Weighted:
+		sum := 0.
+		for i := 0; i < classes; i++ {
+			sum += float64(support[i]) * metric(tp[i], fp[i], fn[i])
+		}
+		return sum / float64(total)
+	case Binary:
+		if classes < 2 {
+			return math.NaN()
+		}
+		return metric(tp[1], fp[1], fn[1])
+	default:
+		sum := 0.
+		for i := 0; i < classes; i++ {
+			sum += metric(tp[i], fp[i], fn[i])
+		}
+		return sum / float64(classes)
+	}
+}
+
+// Log-loss (cross-entropy) metric (lower is better).
+// Outputs are expected to be probabilities and are clipped to the interval [1e-15, 1 - 1e-15].
+func LogLoss(predictor Predictor, samples []Sample) float64 {
+	const eps = 1e-15
+	loss := 0.
+	for _, sample := range samples {
+		expected := sample.Output
+		actual := predictor(sample.Input)
+		for c := 0; c < classCount(expected); c++ {
+			p := math.Min(math.Max(classProbability(actual, c), eps), 1-eps)
+			loss -= classProbability(expected, c) * math.Log(p)
+		}
+	}
+	return loss / float64(len(samples))
+}
+
+// Returns a metric which computes the fraction of samples whose actual class is among the k classes with the largest
+// outputs (higher is better).
+// For binary problems with a single output, top-k accuracy is equal to Accuracy for k equal to 1 and 1 otherwise.
+func TopKAccuracy(k int) Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		correct := 0
+		for _, sample := range samples {
+			class := classOf(sample.Output)
+			actual := predictor(sample.Input)
+			rank := 0
+			for c := 0; c < classCount(sample.Output); c++ {
+				if classProbability(actual, c) > classProbability(actual, class) {
+					rank++
+				}
+			}
+			if rank < k {
+				correct++
+			}
+		}
+		return float64(correct) / float64(len(samples))
+	}
+}
+
+// Area under the receiver operating characteristic curve (higher is better).
+// Binary problems with a single output are scored directly, multiclass problems are scored one-vs-rest and
+// macro averaged over the classes which have both positive and negative samples.
+func ROCAUC(predictor Predictor, samples []Sample) float64 {
+	return oneVsRest(predictor, samples, rocAuc)
+}
+
+// Area under the precision-recall curve, computed as average precision (higher is better).
+// Binary problems with a single output are scored directly, multiclass problems are scored one-vs-rest and
+// macro averaged over the classes which have both positive and negative samples.
+func PRAUC(predictor Predictor, samples []Sample) float64 {
+	return oneVsRest(predictor, samples, averagePrecision)
+}
+
+// Type holding the score of a single sample for a given class and whether the sample belongs to that class.
+type scoredLabel struct {
+	score    float64
+	positive bool
+}
+
+// Computes a binary ranking metric for each class against all other classes and macro averages the results.
+func oneVsRest(predictor Predictor, samples []Sample, metric func([]scoredLabel) float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+
+	outputs := make([][]float64, len(samples))
+	for i, sample := range samples {
+		outputs[i] = predictor(sample.Input)
+	}
+
+	classes := []int{1}
+	if len(samples[0].Output) > 1 {
+		classes = make([]int, len(samples[0].Output))
+		for c := range classes {
+			classes[c] = c
+		}
+	}
+
+	sum, scored := 0., 0
+	for _, c := range classes {
+		labels := make([]scoredLabel, len(samples))
+		positives := 0
+		for i, sample := range samples {
+			labels[i] = scoredLabel{score: classProbability(outputs[i], c), positive: classOf(sample.Output) == c}
+			if labels[i].positive {
+				positives++
+			}
+		}
+		if positives == 0 || positives == len(samples) {
+			continue
+		}
+		sum += metric(labels)
+		scored++
+	}
+	return sum / float64(scored)
+}
+
+// Sorts the labels by score in descending order.
+func sortByScore(labels []scoredLabel) {
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].score > labels[j].score })
+}
+
+// Computes ROC-AUC of the given labels as the normalized Mann-Whitney U statistic, ties count as one half.
+func rocAuc(labels []scoredLabel) float64 {
+	sortByScore(labels)
+	positives, negatives := 0., 0.
+	for _, label := range labels {
+		if label.positive {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+
+	auc, negativesAbove := 0., 0.
+	for i := 0; i < len(labels); {
+		j, groupPositives, groupNegatives := i, 0., 0.
+		for ; j < len(labels) && labels[j].score == labels[i].score; j++ {
+			if labels[j].positive {
+				groupPositives++
+			} else {
+				groupNegatives++
+			}
+		}
+		auc += groupPositives * (negatives - negativesAbove - groupNegatives/2)
+		negativesAbove += groupNegatives
+		i = j
+	}
+	return auc / (positives * negatives)
+}
+
+// Computes the average precision of the given labels, where samples with equal scores are treated as a single
+// threshold.
+func averagePrecision(labels []scoredLabel) float64 {
+	sortByScore(labels)
+	positives := 0.
+	for _, label := range labels {
+		if label.positive {
+			positives++
+		}
+	}
+
+	ap, truePositives, previousRecall := 0., 0., 0.
+	for i := 0; i < len(labels); {
+		j := i
+		for ; j < len(labels) && labels[j].score == labels[i].score; j++ {
+			if labels[j].positive {
+				truePositives++
+			}
+		}
+		currentRecall := truePositives / positives
+		ap += (currentRecall - previousRecall) * truePositives / float64(j)
+		previousRecall = currentRecall
+		i = j
+	}
+	return ap
+}
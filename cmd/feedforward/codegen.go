@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Writes the network of a saved model as standalone Go source.
+func codegen(args []string) error {
+	fs := flag.NewFlagSet("codegen", flag.ContinueOnError)
+	path := fs.String("model", "", "path of the model file")
+	pkg := fs.String("package", "model", "package name of the generated file")
+	output := fs.String("o", "", "path of the generated file, standard output if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	saved, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	if saved.pipeline != nil {
+		return errors.New("preprocessing steps of a pipeline cannot be generated, save the bare network instead")
+	}
+
+	var source bytes.Buffer
+	if err := feedforward.GenerateGo(&source, saved.network, *pkg); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(source.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, source.Bytes(), 0644)
+}
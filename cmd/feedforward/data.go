@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Type holding the flags which describe a data file.
+type dataFlags struct {
+	path         string
+	format       string
+	header       bool
+	comma        string
+	inputs       string
+	outputs      string
+	skip         string
+	inputValues  string
+	inputOutput  string
+	outputValues string
+}
+
+// Registers the data flags on the given flag set.
+func (d *dataFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&d.path, "data", "", "path of the data file")
+	fs.StringVar(&d.format, "format", "", "format of the data file, csv or samples (default: by file extension)")
+	fs.BoolVar(&d.header, "header", false, "whether the first row of a CSV file holds the column names")
+	fs.StringVar(&d.comma, "comma", ",", "field delimiter of a CSV file")
+	fs.StringVar(&d.inputs, "inputs", "", "comma separated input column names or indices of a CSV file (default: all but outputs)")
+	fs.StringVar(&d.outputs, "outputs", "", "comma separated output column names or indices of a CSV file (default: last column)")
+	fs.StringVar(&d.skip, "skip", "", "comma separated column names or indices of a CSV file to ignore")
+	fs.StringVar(&d.inputValues, "input-delimiter", ",", "delimiter between input values in the samples format")
+	fs.StringVar(&d.inputOutput, "io-delimiter", " -> ", "delimiter between input and output in the samples format")
+	fs.StringVar(&d.outputValues, "output-delimiter", ",", "delimiter between output values in the samples format")
+}
+
+// Resolves the format of the data file, falling back to the file extension.
+func (d *dataFlags) resolveFormat() (string, error) {
+	format := d.format
+	if format == "" {
+		format = "samples"
+		if strings.EqualFold(filepath.Ext(d.path), ".csv") {
+			format = "csv"
+		}
+	}
+	if format != "csv" && format != "samples" {
+		return "", fmt.Errorf("unknown data format %q", format)
+	}
+	return format, nil
+}
+
+// Builds the CSV options described by the flags.
+func (d *dataFlags) csvOptions() (feedforward.CSVOptions, error) {
+	options := feedforward.CSVOptions{Header: d.header}
+	comma := []rune(d.comma)
+	if len(comma) != 1 {
+		return options, errors.New("CSV delimiter must be a single character")
+	}
+	options.Comma = comma[0]
+	options.InputColumns, options.InputIndices = splitColumns(d.inputs)
+	options.OutputColumns, options.OutputIndices = splitColumns(d.outputs)
+	options.SkipColumns, options.SkipIndices = splitColumns(d.skip)
+	return options, nil
+}
+
+// Loads the samples of the data file, encoding the columns of a CSV file using the encoders of the given pipeline,
+// which may be nil.
+func (d *dataFlags) load(pipeline *feedforward.Pipeline) ([]feedforward.Sample, error) {
+	if d.path == "" {
+		return nil, errors.New("no data file given, use -data")
+	}
+	format, err := d.resolveFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "samples" {
+		return feedforward.Load(d.path, feedforward.Delimiters{
+			InputValues:  d.inputValues,
+			InputOutput:  d.inputOutput,
+			OutputValues: d.outputValues,
+		})
+	}
+	options, err := d.csvOptions()
+	if err != nil {
+		return nil, err
+	}
+	if pipeline != nil {
+		options = pipeline.CSVOptions(options)
+	}
+	return feedforward.LoadCSV(d.path, options)
+}
+
+// Loads the samples of a CSV data file, one-hot encoding the given categorical input columns and, if classify is true,
+// encoding the class labels of the output column. The encoders are fitted to the file and returned along with the
+// samples, the encoder of the class labels being nil unless classify is true.
+func (d *dataFlags) loadEncoded(categorical []string, classify bool) ([]feedforward.Sample, map[string]feedforward.CategoricalEncoder, *feedforward.LabelEncoder, error) {
+	if d.path == "" {
+		return nil, nil, nil, errors.New("no data file given, use -data")
+	}
+	format, err := d.resolveFormat()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if format != "csv" {
+		return nil, nil, nil, errors.New("categorical columns and class labels require a CSV data file")
+	}
+	options, err := d.csvOptions()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// encoders are stored in the model by column name, so that they do not depend on the order of the columns
+	columns := make(map[string]feedforward.CategoricalEncoder, len(categorical))
+	for _, column := range categorical {
+		if !d.header {
+			return nil, nil, nil, errors.New("categorical columns are selected by name, which requires -header")
+		}
+		columns[column] = feedforward.NewOneHotEncoder(false)
+	}
+	options.Encoders = make(map[string]feedforward.CategoricalEncoder, len(columns)+1)
+	for column, encoder := range columns {
+		options.Encoders[column] = encoder
+	}
+
+	var labels *feedforward.LabelEncoder
+	if classify {
+		if len(options.OutputColumns)+len(options.OutputIndices) != 1 {
+			return nil, nil, nil, errors.New("class labels require a single output column given by -outputs")
+		}
+		labels, _ = feedforward.NewLabelEncoder()
+		if len(options.OutputColumns) == 1 {
+			options.Encoders[options.OutputColumns[0]] = labels
+		} else {
+			options.EncoderIndices = map[int]feedforward.CategoricalEncoder{options.OutputIndices[0]: labels}
+		}
+	}
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer file.Close()
+	if err := feedforward.FitCSVEncoders(file, options); err != nil {
+		return nil, nil, nil, err
+	}
+	samples, err := feedforward.LoadCSV(d.path, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return samples, columns, labels, nil
+}
+
+// Reads the inputs of the data file, which unlike load does not require outputs.
+// Every column of a CSV file which is not skipped or an output is used as input unless input columns are given,
+// outputs of a file in the samples format are ignored. Columns of a CSV file are encoded using the encoders of the
+// given pipeline, which may be nil.
+func (d *dataFlags) loadInputs(pipeline *feedforward.Pipeline) ([][]float64, error) {
+	format, err := d.resolveFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []feedforward.Sample
+	if format == "samples" {
+		if samples, err = d.load(nil); err != nil {
+			return nil, err
+		}
+	} else {
+		var r io.Reader = os.Stdin
+		if d.path != "" && d.path != "-" {
+			file, err := os.Open(d.path)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			r = file
+		}
+		options, err := d.csvOptions()
+		if err != nil {
+			return nil, err
+		}
+		options.InputsOnly = true
+		if pipeline != nil {
+			options = pipeline.CSVOptions(options)
+		}
+		if samples, err = feedforward.ReadCSV(r, options); err != nil {
+			return nil, err
+		}
+	}
+
+	inputs := make([][]float64, len(samples))
+	for i, sample := range samples {
+		inputs[i] = sample.Input
+	}
+	return inputs, nil
+}
+
+// Splits a comma separated list of columns into names and indices.
+func splitColumns(list string) ([]string, []int) {
+	var names []string
+	var indices []int
+	for _, column := range splitList(list) {
+		if index, err := strconv.Atoi(column); err == nil {
+			indices = append(indices, index)
+		} else {
+			names = append(names, column)
+		}
+	}
+	return names, indices
+}
+
+// Splits a comma separated list, ignoring empty elements.
+func splitList(list string) []string {
+	var elements []string
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
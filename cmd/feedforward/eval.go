@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Computes metrics of a saved model on a test file.
+func eval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	var data dataFlags
+	data.register(fs)
+	path := fs.String("model", "", "path of the model file")
+	metrics := fs.String("metrics", "mse", "comma separated names of the metrics to compute")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	saved, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	samples, err := data.load(saved.pipeline)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return errors.New("data file holds no samples")
+	}
+
+	names := splitList(*metrics)
+	scored := make([]feedforward.Metric, len(names))
+	for i, name := range names {
+		if scored[i], err = feedforward.MetricByName(name); err != nil {
+			return err
+		}
+	}
+
+	predictor, failure := feedforward.ModelPredictor(saved.model, len(samples[0].Output))
+	values := make([]float64, len(names))
+	for i, metric := range scored {
+		values[i] = metric(predictor, samples)
+	}
+	if *failure != nil {
+		return *failure
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, name := range names {
+		fmt.Fprintf(w, "%s\t%g\n", name, values[i])
+	}
+	return w.Flush()
+}
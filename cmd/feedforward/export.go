@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Writes the network of a saved model as an ONNX model.
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	path := fs.String("model", "", "path of the model file")
+	output := fs.String("o", "", "path of the ONNX file")
+	matMul := fs.Bool("matmul", false, "export dense layers as MatMul and Add instead of Gemm")
+	single := fs.Bool("float32", false, "store parameters in single precision")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return errors.New("no output file given, use -o")
+	}
+
+	saved, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	if saved.pipeline != nil {
+		return errors.New("preprocessing steps of a pipeline cannot be exported, save the bare network instead")
+	}
+
+	var model bytes.Buffer
+	if err := feedforward.ExportONNX(&model, saved.network, feedforward.ONNXOptions{MatMul: *matMul, Float32: *single}); err != nil {
+		return err
+	}
+	return os.WriteFile(*output, model.Bytes(), 0644)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Converts a model trained by another framework into a saved model.
+func importModel(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	onnx := fs.String("onnx", "", "path of an ONNX model")
+	keras := fs.String("keras", "", "path of a Keras architecture written by model.to_json()")
+	weights := fs.String("weights", "", "path of the Keras weights, a JSON array of the arrays of model.get_weights()")
+	eta := fs.Float64("eta", 0, "learning rate of the imported network, kept if 0 and given by the model")
+	output := fs.String("o", "model.json", "path of the saved model")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var network *feedforward.Network
+	var err error
+	switch {
+	case *onnx != "" && *keras == "":
+		var file *os.File
+		if file, err = os.Open(*onnx); err != nil {
+			return err
+		}
+		network, err = feedforward.ImportONNX(file)
+		file.Close()
+	case *keras != "" && *onnx == "":
+		if *weights == "" {
+			return errors.New("no Keras weights given, use -weights")
+		}
+		network, err = feedforward.LoadKeras(*keras, *weights)
+	default:
+		return errors.New("exactly one of -onnx and -keras must be given")
+	}
+	if err != nil {
+		return err
+	}
+
+	if *eta != 0 {
+		network.SetLearningRate(*eta)
+	}
+	return feedforward.SaveModel(*output, network)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// Prints the topology and parameter counts of a saved model.
+func inspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	path := fs.String("model", "", "path of the model file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	saved, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	if saved.pipeline != nil {
+		for i, step := range saved.pipeline.Steps() {
+			var header struct {
+				Kind string `json:"type"`
+			}
+			encoded, err := json.Marshal(step)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(encoded, &header); err != nil {
+				return err
+			}
+			fmt.Printf("step %d: %s\n", i, header.Kind)
+		}
+	}
+
+	layers := saved.network.Inspect()
+	fmt.Printf("inputs: %d\n", len(layers[0].Weights))
+	fmt.Printf("learning rate: %g\n\n", saved.network.LearningRate())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "layer\tneurons\tactivation\tweights\tbiases\tparameters")
+	total := 0
+	for _, layer := range layers {
+		weights := len(layer.Weights) * len(layer.Biases)
+		parameters := weights + len(layer.Biases)
+		total += parameters
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%d\t%d\n", layer.Layer, len(layer.Biases), layer.Activation, weights, len(layer.Biases), parameters)
+	}
+	fmt.Fprintf(w, "total\t\t\t\t\t%d\n", total)
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+// Command feedforward trains, evaluates and inspects feedforward neural networks without writing a Go program.
+//
+// Usage:
+//
+//	feedforward train   -data train.csv -hidden 8 -activations relu,sigmoid -o model.json
+//	feedforward eval    -model model.json -data test.csv -metrics accuracy,f1
+//	feedforward predict -model model.json -data inputs.csv -o predictions.csv
+//	feedforward inspect -model model.json
+//	feedforward serve   -model model.json -addr :8080
+//	feedforward codegen -model model.json -package model -o model/model.go
+//	feedforward export  -model model.json -o model.onnx
+//	feedforward import  -keras keras.json -weights weights.json -o model.json
+//
+// Run feedforward <command> -h for the flags of every command.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Type representing a subcommand which receives the arguments following its name.
+type command func(args []string) error
+
+var commands = map[string]command{
+	"train":   train,
+	"eval":    eval,
+	"predict": predict,
+	"inspect": inspect,
+	"serve":   serveModel,
+	"codegen": codegen,
+	"export":  export,
+	"import":  importModel,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "feedforward: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "feedforward:", err)
+		os.Exit(1)
+	}
+}
+
+// Prints the list of commands to the standard error.
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: feedforward <command> [flags]
+
+Commands:
+  train    train a network and save it to a model file
+  eval     compute metrics of a saved model on a test file
+  predict  write predictions of a saved model as CSV
+  inspect  print the topology and parameter counts of a saved model
+  serve    serve predictions of a saved model over HTTP
+  codegen  write the network of a saved model as standalone Go source
+  export   write the network of a saved model as an ONNX model
+  import   convert an ONNX or Keras model into a saved model`)
+}
+
+// Type holding a model loaded from a file, which is either a bare Network or a Pipeline.
+type savedModel struct {
+	model    feedforward.Model
+	network  *feedforward.Network
+	pipeline *feedforward.Pipeline
+}
+
+// Loads a model saved by the train command, detecting whether it is a Network or a Pipeline.
+func loadModel(path string) (*savedModel, error) {
+	if path == "" {
+		return nil, errors.New("no model file given, use -model")
+	}
+	model, err := feedforward.LoadModel(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch model := model.(type) {
+	case *feedforward.Pipeline:
+		return &savedModel{model: model, network: model.Network(), pipeline: model}, nil
+	default:
+		return &savedModel{model: model, network: model.(*feedforward.Network)}, nil
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Writes the predictions of a saved model for the inputs of a data file as CSV.
+func predict(args []string) error {
+	fs := flag.NewFlagSet("predict", flag.ContinueOnError)
+	var data dataFlags
+	data.register(fs)
+	path := fs.String("model", "", "path of the model file")
+	output := fs.String("o", "-", "path of the predictions file, - writes to the standard output")
+	labels := fs.String("labels", "", "comma separated class labels, writes the predicted label instead of the raw outputs, defaults to the labels of a saved pipeline")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	saved, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	inputs, err := data.loadInputs(saved.pipeline)
+	if err != nil {
+		return err
+	}
+
+	var encoder *feedforward.LabelEncoder
+	if classes := splitList(*labels); len(classes) > 0 {
+		if encoder, err = feedforward.NewLabelEncoder(classes...); err != nil {
+			return err
+		}
+	} else if saved.pipeline != nil {
+		encoder = saved.pipeline.Labels()
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	writer := csv.NewWriter(w)
+	for _, input := range inputs {
+		prediction, err := saved.model.Predict(input)
+		if err != nil {
+			return err
+		}
+
+		var record []string
+		if encoder != nil {
+			label, err := encoder.Decode(prediction)
+			if err != nil {
+				return err
+			}
+			record = []string{label}
+		} else {
+			record = make([]string, len(prediction))
+			for i, value := range prediction {
+				record[i] = strconv.FormatFloat(value, 'g', -1, 64)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
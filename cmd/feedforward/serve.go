@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/andrijadukic/feedforward/serve"
+	"github.com/andrijadukic/feedforward/serve/grpcserve"
+)
+
+// Serves a saved model over HTTP, and optionally gRPC, until interrupted, reloading it from disk on SIGHUP.
+func serveModel(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	path := fs.String("model", "", "path of the model file")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	grpcAddr := fs.String("grpc-addr", "", "address to serve gRPC on, disabled if empty")
+	maxBatch := fs.Int("max-batch", 0, "maximum number of inputs of a single request, 0 is unlimited")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("no model file given, use -model")
+	}
+
+	model, err := serve.Load(*path)
+	if err != nil {
+		return err
+	}
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			if err := model.Reload(); err != nil {
+				fmt.Fprintln(os.Stderr, "reload failed, keeping the current model:", err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "reloaded %s, revision %d\n", *path, model.Metadata().Revision)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *grpcAddr == "" {
+		fmt.Fprintf(os.Stderr, "serving %s on %s\n", *path, *addr)
+		return serve.NewServer(model, serve.Options{MaxBatchSize: *maxBatch}).ListenAndServe(ctx, *addr)
+	}
+
+	// a server which fails stops the other one as well
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	failed := make(chan error, 1)
+	go func() {
+		err := grpcserve.ListenAndServe(ctx, model, *grpcAddr)
+		cancel()
+		failed <- err
+	}()
+	fmt.Fprintf(os.Stderr, "serving %s on %s, gRPC on %s\n", *path, *addr, *grpcAddr)
+	err = serve.NewServer(model, serve.Options{MaxBatchSize: *maxBatch}).ListenAndServe(ctx, *addr)
+	cancel()
+	if grpcErr := <-failed; err == nil {
+		err = grpcErr
+	}
+	return err
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Trains a network on a data file and saves it to a model file.
+func train(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	var data dataFlags
+	data.register(fs)
+	config := fs.String("config", "", "JSON or YAML file describing the network and its training, replaces the network and training flags")
+	neurons := fs.String("neurons", "", "comma separated number of neurons of every layer, including the input and output layer")
+	hidden := fs.String("hidden", "", "comma separated number of neurons of every hidden layer, the input and output layer are taken from the data")
+	activations := fs.String("activations", "sigmoid", "comma separated activation function of every layer, or a single one used for all layers")
+	initializer := fs.String("initializer", "uniform:-1:1", "weight initializer: uniform:<lower>:<upper>, gaussian:<mean>:<stddev> or xavier")
+	optimizer := fs.String("optimizer", "sgd", "optimizer used for training, only online sgd is supported")
+	eta := fs.Float64("eta", 0.1, "learning rate")
+	loss := fs.String("loss", "mse", "metric used as the training score reported to the stopping conditions and the log, metrics where higher is better are reported as 1 minus the metric")
+	maxIter := fs.Int("max-iter", 1000, "maximum number of epochs")
+	precision := fs.Float64("precision", 0, "stop when the training score drops to this value, 0 disables")
+	maxDuration := fs.Duration("max-duration", 0, "stop after this wall-clock duration, 0 disables")
+	patience := fs.Int("patience", 0, "stop when the score did not improve by min-delta for this many epochs, 0 disables")
+	minDelta := fs.Float64("min-delta", 0, "minimal improvement of the score used by -patience")
+	scale := fs.String("scale", "none", "feature scaling: none, standard, minmax, robust or maxabs")
+	scaleOutputs := fs.Bool("scale-outputs", false, "whether outputs are scaled as well as inputs")
+	categorical := fs.String("categorical", "", "comma separated names of categorical input columns of a CSV file, which are one-hot encoded, requires -header")
+	classify := fs.Bool("classify", false, "whether the output column of a CSV file, given by -outputs, holds class labels, which are one-hot encoded")
+	logEvery := fs.Int("log-every", 0, "print the training score every n epochs to the standard output, 0 disables")
+	output := fs.String("o", "model.json", "path of the saved model")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var samples []feedforward.Sample
+	var columns map[string]feedforward.CategoricalEncoder
+	var labels *feedforward.LabelEncoder
+	var err error
+	if *categorical != "" || *classify {
+		samples, columns, labels, err = data.loadEncoded(splitList(*categorical), *classify)
+	} else {
+		samples, err = data.load(nil)
+	}
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return errors.New("data file holds no samples")
+	}
+
+	var network *feedforward.Network
+	if *config != "" {
+		built, closer, err := buildFromConfig(*config, samples[0])
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		network = built
+	} else {
+		if *optimizer != "sgd" {
+			return fmt.Errorf("unknown optimizer %q", *optimizer)
+		}
+		topology, err := parseNeurons(*neurons, *hidden, samples[0])
+		if err != nil {
+			return err
+		}
+		functions, err := parseActivations(*activations, len(topology)-1)
+		if err != nil {
+			return err
+		}
+		weights, err := parseInitializer(*initializer)
+		if err != nil {
+			return err
+		}
+		score, err := feedforward.LossByName(*loss)
+		if err != nil {
+			return err
+		}
+
+		stop := feedforward.NewMaxIter(*maxIter)
+		if *precision > 0 {
+			stop = stop.Or(feedforward.NewPrecision(*precision))
+		}
+		if *maxDuration > 0 {
+			stop = stop.Or(feedforward.NewMaxDuration(*maxDuration))
+		}
+		if *patience > 0 {
+			stop = stop.Or(feedforward.NewPlateau(*patience, *minDelta))
+		}
+
+		network = feedforward.NewNetwork(topology, functions, weights, stop, *eta)
+		network.SetLoss(score)
+	}
+	if *logEvery > 0 {
+		network.AddObserver(feedforward.NewNthIterationObserver(feedforward.NewStOutLogger(), *logEvery))
+	}
+
+	var model interface {
+		FitContext(ctx context.Context, samples []feedforward.Sample) error
+		MarshalJSON() ([]byte, error)
+	} = network
+	var steps []feedforward.Transformer
+	if *scale != "none" {
+		scaler, err := parseScaler(*scale, *scaleOutputs)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, scaler)
+	}
+	// the encoders are stored in a pipeline, so that predict and eval encode the data the same way
+	if len(steps) > 0 || len(columns) > 0 || labels != nil {
+		pipeline := feedforward.NewPipeline(network, steps...)
+		pipeline.SetEncoders(columns, labels)
+		model = pipeline
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignals()
+	start := time.Now()
+	err = model.FitContext(ctx, samples)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "training interrupted, saving the parameters learned so far")
+	}
+
+	if err := feedforward.SaveModel(*output, model); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "trained on %d samples in %v, saved to %s\n", len(samples), time.Since(start).Round(time.Millisecond), *output)
+	return nil
+}
+
+// Builds a network from a configuration file, taking the number of inputs from the sample if the file omits it.
+func buildFromConfig(path string, sample feedforward.Sample) (*feedforward.Network, io.Closer, error) {
+	config, err := feedforward.LoadConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.Inputs == 0 {
+		config.Inputs = len(sample.Input)
+	}
+	if config.Inputs != len(sample.Input) {
+		return nil, nil, fmt.Errorf("%s: network has %d inputs, but the data has %d", path, config.Inputs, len(sample.Input))
+	}
+	if n := len(config.Layers); n > 0 && config.Layers[n-1].Neurons != len(sample.Output) {
+		return nil, nil, fmt.Errorf("%s: network has %d outputs, but the data has %d", path, config.Layers[n-1].Neurons, len(sample.Output))
+	}
+
+	network, closer, err := config.Build()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return network, closer, nil
+}
+
+// Parses the topology of the network, given either as all layers or as the hidden layers only, in which case the input
+// and output layer are taken from the sample. Without either, the network has no hidden layers.
+func parseNeurons(all, hidden string, sample feedforward.Sample) ([]int, error) {
+	inputs, outputs := len(sample.Input), len(sample.Output)
+	if all != "" && hidden != "" {
+		return nil, errors.New("-neurons and -hidden cannot be used together")
+	}
+	if all == "" {
+		neurons, err := parseCounts(hidden)
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]int{inputs}, neurons...), outputs), nil
+	}
+
+	neurons, err := parseCounts(all)
+	if err != nil {
+		return nil, err
+	}
+	if len(neurons) < 2 {
+		return nil, errors.New("-neurons requires at least an input and an output layer, use -hidden to give the hidden layers only")
+	}
+	if neurons[0] != inputs {
+		return nil, fmt.Errorf("input layer has %d neurons, but the data has %d inputs", neurons[0], inputs)
+	}
+	if neurons[len(neurons)-1] != outputs {
+		return nil, fmt.Errorf("output layer has %d neurons, but the data has %d outputs", neurons[len(neurons)-1], outputs)
+	}
+	return neurons, nil
+}
+
+// Parses a comma separated list of positive numbers of neurons.
+func parseCounts(list string) ([]int, error) {
+	var counts []int
+	for _, element := range splitList(list) {
+		count, err := strconv.Atoi(element)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid number of neurons %q", element)
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// Parses the activation functions of the given number of layers.
+func parseActivations(list string, layers int) ([]feedforward.ActivationFunction, error) {
+	names := splitList(list)
+	if len(names) == 1 {
+		activation, err := feedforward.ActivationByName(names[0])
+		if err != nil {
+			return nil, err
+		}
+		return feedforward.Repeat(activation, layers), nil
+	}
+	if len(names) != layers {
+		return nil, fmt.Errorf("got %d activation functions for %d layers", len(names), layers)
+	}
+
+	activations := make([]feedforward.ActivationFunction, layers)
+	for i, name := range names {
+		activation, err := feedforward.ActivationByName(name)
+		if err != nil {
+			return nil, err
+		}
+		activations[i] = activation
+	}
+	return activations, nil
+}
+
+// Parses an initializer given as its name followed by colon separated parameters.
+func parseInitializer(spec string) (feedforward.Initializer, error) {
+	parts := strings.Split(spec, ":")
+	parameters := make([]float64, len(parts)-1)
+	for i, part := range parts[1:] {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid initializer parameter %q", part)
+		}
+		parameters[i] = value
+	}
+
+	switch {
+	case parts[0] == "uniform" && len(parameters) == 2:
+		return feedforward.NewUniformInitializer(parameters[0], parameters[1]), nil
+	case parts[0] == "gaussian" && len(parameters) == 2:
+		return feedforward.NewGaussianInitializer(parameters[0], parameters[1]), nil
+	case parts[0] == "xavier" && len(parameters) == 0:
+		return feedforward.NewXavierInitializer(0, 0), nil
+	default:
+		return nil, fmt.Errorf("invalid initializer %q", spec)
+	}
+}
+
+// Creates the scaler with the given name.
+func parseScaler(name string, scaleOutputs bool) (feedforward.Transformer, error) {
+	switch name {
+	case "standard":
+		return feedforward.NewStandardScaler(scaleOutputs), nil
+	case "minmax":
+		return feedforward.NewMinMaxScaler(0, 1, scaleOutputs)
+	case "robust":
+		return feedforward.NewRobustScaler(scaleOutputs), nil
+	case "maxabs":
+		return feedforward.NewMaxAbsScaler(scaleOutputs), nil
+	default:
+		return nil, fmt.Errorf("unknown scaler %q", name)
+	}
+}
@@ -0,0 +1,130 @@
+package feedforward
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Source of the activation functions available to generated code, which mirror the built-in activation functions.
+var generatedActivations = map[string]string{
+	"sigmoid": "func sigmoid(net float64) float64 { return 1 / (1 + math.Exp(-net)) }",
+	"tanh":    "func tanh(net float64) float64 { return (1 - math.Exp(-2*net)) / (1 + math.Exp(-2*net)) }",
+	"relu":    "func relu(net float64) float64 { return math.Max(net, 0) }",
+	"linear":  "func linear(net float64) float64 { return net }",
+}
+
+// Writes the given fitted network as a standalone Go source file of the given package, which depends only on the
+// standard library.
+// The generated file holds the parameters of the network as arrays and a Predict function computing the output of
+// the network without allocating, with every neuron unrolled:
+//
+//	func Predict(input *[Inputs]float64) (output [Outputs]float64)
+//
+// Outputs match the outputs of Network.Predict within floating point tolerance. Only networks using the built-in
+// activation functions can be generated. Every generated network should be given its own package, as the file
+// declares the identifiers Inputs, Outputs and Predict along with unexported helpers.
+func GenerateGo(w io.Writer, network *Network, pkg string) error {
+	if !network.isFitted {
+		return errors.New("this instance of Network has not been fitted yet")
+	}
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	used := make(map[string]bool)
+	for k, activation := range network.activations {
+		if _, ok := generatedActivations[activation.Name]; !ok {
+			return fmt.Errorf("activation function %q of layer %d cannot be generated", activation.Name, k)
+		}
+		used[activation.Name] = true
+	}
+
+	var b bytes.Buffer
+	last := len(network.layers) - 1
+	fmt.Fprintf(&b, "// Code generated by feedforward. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// Package %s computes the output of a feedforward neural network of topology %v, whose parameters are embedded\n", pkg, network.neurons)
+	fmt.Fprintf(&b, "// in the source.\npackage %s\n\n", pkg)
+	// the linear activation function is the only one which does not use the math package
+	for name := range used {
+		if name != "linear" {
+			b.WriteString("import \"math\"\n\n")
+			break
+		}
+	}
+	fmt.Fprintf(&b, "const (\n\t// Number of inputs of Predict.\n\tInputs = %d\n\t// Number of outputs of Predict.\n\tOutputs = %d\n)\n\n",
+		network.neurons[0], network.neurons[last+1])
+
+	for k, l := range network.layers {
+		weights, biases := l.getWeights(), l.getBiases()
+		fmt.Fprintf(&b, "// Weights of layer %d, indexed as weights%d[i][j], i being a neuron of the previous layer and j a neuron of this layer.\n", k, k)
+		fmt.Fprintf(&b, "var weights%d = [%d][%d]float64{\n", k, len(weights), len(biases))
+		for i, row := range weights {
+			if err := writeFloats(&b, row); err != nil {
+				return fmt.Errorf("weights of layer %d, neuron %d: %w", k, i, err)
+			}
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, "}\n\n// Biases of layer %d.\nvar biases%d = [%d]float64", k, k, len(biases))
+		if err := writeFloats(&b, biases); err != nil {
+			return fmt.Errorf("biases of layer %d: %w", k, err)
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("// Predict computes the output of the network for the given input without allocating.\n")
+	b.WriteString("// A slice holding Inputs values can be passed by converting it using (*[Inputs]float64)(slice).\n")
+	b.WriteString("func Predict(input *[Inputs]float64) (output [Outputs]float64) {\n")
+	previous := "input"
+	for k, l := range network.layers {
+		current := "layer" + strconv.Itoa(k)
+		if k == last {
+			current = "output"
+		} else {
+			fmt.Fprintf(&b, "\tvar %s [%d]float64\n", current, len(l.getBiases()))
+		}
+		for j := range l.getBiases() {
+			fmt.Fprintf(&b, "\t%s[%d] = %s(biases%d[%d]", current, j, network.activations[k].Name, k, j)
+			for i := range l.getWeights() {
+				fmt.Fprintf(&b, " + weights%d[%d][%d]*%s[%d]", k, i, j, previous, i)
+			}
+			b.WriteString(")\n")
+		}
+		previous = current
+	}
+	b.WriteString("\treturn output\n}\n")
+
+	for _, name := range []string{"sigmoid", "tanh", "relu", "linear"} {
+		if used[name] {
+			fmt.Fprintf(&b, "\n%s\n", generatedActivations[name])
+		}
+	}
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+// Writes the given values as the elements of an array literal.
+func writeFloats(b *bytes.Buffer, values []float64) error {
+	b.WriteString("{")
+	for i, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("value %v cannot be generated", value)
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	b.WriteString("}")
+	return nil
+}
@@ -0,0 +1,416 @@
+package feedforward
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Type holding a declarative description of a network and its training, which can be decoded from JSON or YAML.
+// Inputs is the number of input neurons, Layers describe every following layer up to and including the output layer.
+// Optional fields default to a uniform initializer on [-1, 1], the mse loss, online SGD and a constant learning rate.
+// Loss is one of the names accepted by LossByName, so metrics where higher is better are reported to the stopping
+// conditions and observers as 1 minus the metric.
+// A stopping condition is required, as a network without one would never stop training.
+type Config struct {
+	Inputs        int               `json:"inputs"`
+	Layers        []LayerConfig     `json:"layers"`
+	Initializer   InitializerConfig `json:"initializer"`
+	Loss          string            `json:"loss,omitempty"`
+	Optimizer     OptimizerConfig   `json:"optimizer"`
+	Schedule      *ScheduleConfig   `json:"schedule,omitempty"`
+	Stopping      StoppingConfig    `json:"stopping"`
+	Observers     []ObserverConfig  `json:"observers,omitempty"`
+	ShuffleBuffer int               `json:"shuffle_buffer,omitempty"`
+}
+
+// Type holding the description of a single layer, the activation is one of the names accepted by ActivationByName.
+type LayerConfig struct {
+	Neurons    int    `json:"neurons"`
+	Activation string `json:"activation"`
+}
+
+// Type holding the description of a weight initializer.
+// Type is one of uniform (using Lower and Upper), gaussian (using Mean and Stddev) or xavier.
+type InitializerConfig struct {
+	Type   string  `json:"type,omitempty"`
+	Lower  float64 `json:"lower,omitempty"`
+	Upper  float64 `json:"upper,omitempty"`
+	Mean   float64 `json:"mean,omitempty"`
+	Stddev float64 `json:"stddev,omitempty"`
+}
+
+// Type holding the description of the optimizer, online SGD being the only supported type.
+type OptimizerConfig struct {
+	Type         string  `json:"type,omitempty"`
+	LearningRate float64 `json:"learning_rate"`
+}
+
+// Type holding the description of a learning rate schedule.
+// Type is one of constant, step (using Every and Factor), exponential (using Rate) or inverse_time (using Rate).
+type ScheduleConfig struct {
+	Type   string  `json:"type"`
+	Every  int     `json:"every,omitempty"`
+	Factor float64 `json:"factor,omitempty"`
+	Rate   float64 `json:"rate,omitempty"`
+}
+
+// Type holding the description of a stopping condition, which may be composed of other stopping conditions.
+// Type is one of max_iter (using Iterations), precision (using Threshold), max_duration (using Duration, such as
+// "10m"), plateau and relative_plateau (using Patience and MinDelta), divergence (using Consecutive), gradient_norm
+// and update_norm (using Threshold), and and or (combining at least two Conditions) or not (inverting Condition).
+type StoppingConfig struct {
+	Type        string           `json:"type"`
+	Iterations  int              `json:"iterations,omitempty"`
+	Threshold   float64          `json:"threshold,omitempty"`
+	Duration    string           `json:"duration,omitempty"`
+	Patience    int              `json:"patience,omitempty"`
+	MinDelta    float64          `json:"min_delta,omitempty"`
+	Consecutive int              `json:"consecutive,omitempty"`
+	Conditions  []StoppingConfig `json:"conditions,omitempty"`
+	Condition   *StoppingConfig  `json:"condition,omitempty"`
+}
+
+// Type holding the description of an observer.
+// Type is one of stdout, csv and jsonl (writing to Path, or to the standard output if Path is "-") or tensorboard
+// (writing to the log directory Path, with weight histograms every HistogramEvery Iterations).
+// If Every is greater than 1, the observer only receives every Every-th Iteration.
+type ObserverConfig struct {
+	Type           string `json:"type"`
+	Path           string `json:"path,omitempty"`
+	Every          int    `json:"every,omitempty"`
+	HistogramEvery int    `json:"histogram_every,omitempty"`
+}
+
+// Decodes a configuration from JSON, rejecting unknown fields.
+func ParseJSONConfig(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	config := &Config{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Decodes a configuration from YAML, rejecting unknown fields.
+// The document is converted to JSON, so fields are named and validated the same way as in JSON configurations.
+func ParseYAMLConfig(data []byte) (*Config, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONConfig(encoded)
+}
+
+// Loads a configuration from a file, which is decoded as YAML if its extension is .yaml or .yml and as JSON otherwise.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config *Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		config, err = ParseYAMLConfig(data)
+	default:
+		config, err = ParseJSONConfig(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Validates the configuration, returning an error which lists every problem found along with the path of the field
+// which caused it.
+func (c *Config) Validate() error {
+	var problems []string
+	report := func(path, format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if c.Inputs <= 0 {
+		report("inputs", "must be positive")
+	}
+	if len(c.Layers) == 0 {
+		report("layers", "at least one layer is required")
+	}
+	for i, layer := range c.Layers {
+		if layer.Neurons <= 0 {
+			report(fmt.Sprintf("layers[%d].neurons", i), "must be positive")
+		}
+		if _, err := ActivationByName(layer.Activation); err != nil {
+			report(fmt.Sprintf("layers[%d].activation", i), "%v", err)
+		}
+	}
+
+	switch c.Initializer.Type {
+	case "", "xavier":
+	case "uniform":
+		if c.Initializer.Lower >= c.Initializer.Upper {
+			report("initializer", "lower must be less than upper")
+		}
+	case "gaussian":
+		if c.Initializer.Stddev <= 0 {
+			report("initializer.stddev", "must be positive")
+		}
+	default:
+		report("initializer.type", "unknown initializer %q", c.Initializer.Type)
+	}
+
+	if c.Loss != "" {
+		if _, err := LossByName(c.Loss); err != nil {
+			report("loss", "%v", err)
+		}
+	}
+
+	if c.Optimizer.Type != "" && c.Optimizer.Type != "sgd" {
+		report("optimizer.type", "unknown optimizer %q, only sgd is supported", c.Optimizer.Type)
+	}
+	if c.Optimizer.LearningRate <= 0 {
+		report("optimizer.learning_rate", "must be positive")
+	}
+
+	if s := c.Schedule; s != nil {
+		switch s.Type {
+		case "constant":
+		case "step":
+			if s.Every <= 0 {
+				report("schedule.every", "must be positive")
+			}
+			if s.Factor <= 0 {
+				report("schedule.factor", "must be positive")
+			}
+		case "exponential", "inverse_time":
+			if s.Rate < 0 {
+				report("schedule.rate", "must not be negative")
+			}
+		default:
+			report("schedule.type", "unknown schedule %q", s.Type)
+		}
+	}
+
+	c.Stopping.validate("stopping", report)
+
+	for i, observer := range c.Observers {
+		path := fmt.Sprintf("observers[%d]", i)
+		switch observer.Type {
+		case "stdout":
+		case "csv", "jsonl", "tensorboard":
+			if observer.Path == "" {
+				report(path+".path", "is required for %s observers", observer.Type)
+			}
+		default:
+			report(path+".type", "unknown observer %q", observer.Type)
+		}
+		if observer.Every < 0 {
+			report(path+".every", "must not be negative")
+		}
+	}
+
+	if c.ShuffleBuffer < 0 {
+		report("shuffle_buffer", "must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Validates the stopping condition and all conditions it is composed of.
+func (s *StoppingConfig) validate(path string, report func(path, format string, args ...interface{})) {
+	switch s.Type {
+	case "":
+		report(path, "a stopping condition is required")
+	case "max_iter":
+		if s.Iterations <= 0 {
+			report(path+".iterations", "must be positive")
+		}
+	case "precision", "gradient_norm", "update_norm":
+	case "max_duration":
+		if duration, err := time.ParseDuration(s.Duration); err != nil || duration <= 0 {
+			report(path+".duration", "must be a positive duration such as \"10m\"")
+		}
+	case "plateau", "relative_plateau":
+		if s.Patience <= 0 {
+			report(path+".patience", "must be positive")
+		}
+	case "divergence":
+		if s.Consecutive <= 0 {
+			report(path+".consecutive", "must be positive")
+		}
+	case "and", "or":
+		if len(s.Conditions) < 2 {
+			report(path+".conditions", "%s requires at least two conditions", s.Type)
+		}
+		for i := range s.Conditions {
+			s.Conditions[i].validate(fmt.Sprintf("%s.conditions[%d]", path, i), report)
+		}
+	case "not":
+		if s.Condition == nil {
+			report(path+".condition", "not requires a condition")
+		} else {
+			s.Condition.validate(path+".condition", report)
+		}
+	default:
+		report(path+".type", "unknown stopping condition %q", s.Type)
+	}
+}
+
+// Builds the stopping condition described by a valid configuration.
+func (s *StoppingConfig) build() StoppingCondition {
+	switch s.Type {
+	case "max_iter":
+		return NewMaxIter(s.Iterations)
+	case "precision":
+		return NewPrecision(s.Threshold)
+	case "max_duration":
+		duration, _ := time.ParseDuration(s.Duration)
+		return NewMaxDuration(duration)
+	case "plateau":
+		return NewPlateau(s.Patience, s.MinDelta)
+	case "relative_plateau":
+		return NewRelativePlateau(s.Patience, s.MinDelta)
+	case "divergence":
+		return NewDivergence(s.Consecutive)
+	case "gradient_norm":
+		return NewGradientNorm(s.Threshold)
+	case "update_norm":
+		return NewUpdateNorm(s.Threshold)
+	case "and", "or":
+		condition := s.Conditions[0].build()
+		for i := 1; i < len(s.Conditions); i++ {
+			if s.Type == "and" {
+				condition = condition.And(s.Conditions[i].build())
+			} else {
+				condition = condition.Or(s.Conditions[i].build())
+			}
+		}
+		return condition
+	default:
+		return s.Condition.build().Not()
+	}
+}
+
+// Type which closes every resource opened for the observers of a configuration.
+type closers []io.Closer
+
+// Closes every resource, returning the first error.
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Validates the configuration and builds a ready to train Network, with the described observers attached.
+// The returned Closer closes the files opened by the observers and must be called once training is done.
+func (c *Config) Build() (*Network, io.Closer, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	neurons := make([]int, len(c.Layers)+1)
+	activations := make([]ActivationFunction, len(c.Layers))
+	neurons[0] = c.Inputs
+	for i, layer := range c.Layers {
+		neurons[i+1] = layer.Neurons
+		activations[i], _ = ActivationByName(layer.Activation)
+	}
+
+	var initializer Initializer
+	switch c.Initializer.Type {
+	case "":
+		initializer = NewUniformInitializer(-1, 1)
+	case "uniform":
+		initializer = NewUniformInitializer(c.Initializer.Lower, c.Initializer.Upper)
+	case "gaussian":
+		initializer = NewGaussianInitializer(c.Initializer.Mean, c.Initializer.Stddev)
+	case "xavier":
+		initializer = NewXavierInitializer(0, 0)
+	}
+
+	network := NewNetwork(neurons, activations, initializer, c.Stopping.build(), c.Optimizer.LearningRate)
+	if c.Loss != "" {
+		loss, _ := LossByName(c.Loss)
+		network.SetLoss(loss)
+	}
+	if c.ShuffleBuffer > 0 {
+		network.SetShuffleBuffer(c.ShuffleBuffer)
+	}
+	if s := c.Schedule; s != nil {
+		switch s.Type {
+		case "constant":
+			network.SetSchedule(NewConstantSchedule())
+		case "step":
+			network.SetSchedule(NewStepDecay(s.Every, s.Factor))
+		case "exponential":
+			network.SetSchedule(NewExponentialDecay(s.Rate))
+		case "inverse_time":
+			network.SetSchedule(NewInverseTimeDecay(s.Rate))
+		}
+	}
+
+	var opened closers
+	for i, o := range c.Observers {
+		observer, closer, err := o.build()
+		if err != nil {
+			_ = opened.Close()
+			return nil, nil, fmt.Errorf("observers[%d]: %w", i, err)
+		}
+		if closer != nil {
+			opened = append(opened, closer)
+		}
+		if o.Every > 1 {
+			observer = NewNthIterationObserver(observer, o.Every)
+		}
+		network.AddObserver(observer)
+	}
+	return network, opened, nil
+}
+
+// Builds the observer described by a valid configuration, along with the resource it opened, if any.
+func (o *ObserverConfig) build() (ModelObserver, io.Closer, error) {
+	if o.Type == "stdout" {
+		return NewStOutLogger(), nil, nil
+	}
+	if o.Type == "tensorboard" {
+		writer, err := NewTensorBoardWriter(o.Path, TensorBoardOptions{HistogramEvery: o.HistogramEvery})
+		if err != nil {
+			return nil, nil, err
+		}
+		return writer, writer, nil
+	}
+
+	var w io.Writer = os.Stdout
+	var closer io.Closer
+	if o.Path != "-" {
+		file, err := os.Create(o.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, closer = file, file
+	}
+	options := TrainingLogOptions{}
+	if o.Type == "csv" {
+		return NewCSVLogger(w, options), closer, nil
+	}
+	return NewJSONLinesLogger(w, options), closer, nil
+}
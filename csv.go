@@ -0,0 +1,377 @@
+package feedforward
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Represents the way missing values in a CSV file are handled.
+type MissingValuePolicy int
+
+const (
+	// Missing values cause an error.
+	MissingError MissingValuePolicy = iota
+	// Rows with missing values in any selected column are skipped.
+	MissingSkip
+	// Missing values are replaced with CSVOptions.FillValue.
+	MissingFill
+)
+
+// Type holding the options used for loading samples from a CSV file.
+// Columns can be selected by name, which requires a header row, or by zero based index.
+// If no input columns are selected, every column which is not an output and is not skipped is used as input.
+// If no output columns are selected, the last column which is not skipped is used as output.
+// Columns holding categorical string values are encoded by the encoder assigned to them, which expands the column
+// into as many values as the dimension of the encoder. Encoders must be fitted before loading, see FitCSVEncoders.
+type CSVOptions struct {
+	// Field delimiter, defaults to ','.
+	Comma rune
+	// Lines beginning with this character are ignored, 0 disables comments.
+	Comment rune
+	// Whether the first record is a header row holding the column names.
+	Header bool
+
+	InputColumns  []string
+	InputIndices  []int
+	OutputColumns []string
+	OutputIndices []int
+	SkipColumns   []string
+	SkipIndices   []int
+
+	Encoders       map[string]CategoricalEncoder
+	EncoderIndices map[int]CategoricalEncoder
+
+	// Whether the file may hold inputs only, such as inputs to predict on. No output column is then selected unless
+	// output columns are given, in which case they are not used as inputs.
+	InputsOnly bool
+
+	// Values which are considered missing, defaults to "", "NA" and "NaN".
+	MissingValues []string
+	Missing       MissingValuePolicy
+	FillValue     float64
+}
+
+// Function for loading samples from a CSV file into a slice
+func LoadCSV(path string, options CSVOptions) ([]Sample, error) {
+	return ReadAll(NewCSVSource(path, options))
+}
+
+// Function for reading samples in CSV format from the given reader into a slice
+func ReadCSV(r io.Reader, options CSVOptions) ([]Sample, error) {
+	return ReadAll(SourceFunc(func() (SampleIterator, error) { return newCSVIterator(r, nil, options), nil }))
+}
+
+// Source which opens the CSV file at the given path on every call to Open.
+type csvSource struct {
+	path    string
+	options CSVOptions
+}
+
+// Constructor of a DataSource which lazily reads samples from a CSV file.
+// The file is reopened on every pass, so it is never loaded into memory as a whole.
+func NewCSVSource(path string, options CSVOptions) DataSource {
+	return &csvSource{path: path, options: options}
+}
+
+// Opens the file and returns an iterator over its samples.
+func (c *csvSource) Open() (SampleIterator, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+	return newCSVIterator(file, file, c.options), nil
+}
+
+// Iterator which lazily parses samples from a CSV reader.
+type csvIterator struct {
+	reader    *csv.Reader
+	closer    io.Closer
+	options   CSVOptions
+	isMissing map[string]bool
+
+	started  bool
+	names    []string
+	inputs   []int
+	outputs  []int
+	encoders map[int]CategoricalEncoder
+}
+
+// Constructor of a csvIterator, closer may be nil if the underlying reader does not need to be closed.
+func newCSVIterator(r io.Reader, closer io.Closer, options CSVOptions) SampleIterator {
+	reader := csv.NewReader(r)
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+	reader.Comment = options.Comment
+	reader.ReuseRecord = true
+
+	return &csvIterator{reader: reader, closer: closer, options: options, isMissing: missingValues(options)}
+}
+
+// Returns the set of values considered missing by the given options.
+func missingValues(options CSVOptions) map[string]bool {
+	missing := options.MissingValues
+	if missing == nil {
+		missing = []string{"", "NA", "NaN"}
+	}
+	isMissing := make(map[string]bool, len(missing))
+	for _, value := range missing {
+		isMissing[value] = true
+	}
+	return isMissing
+}
+
+// Parses the next record into a sample, skipping the header and records with missing values if configured.
+func (c *csvIterator) Next() (Sample, error) {
+	for {
+		record, err := c.reader.Read()
+		if err != nil {
+			return Sample{}, err
+		}
+		line, _ := c.reader.FieldPos(0)
+
+		if !c.started {
+			c.started = true
+			if c.options.Header {
+				c.names = append([]string(nil), record...)
+			}
+			c.inputs, c.outputs, err = selectColumns(len(record), c.names, c.options)
+			if err != nil {
+				return Sample{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			c.encoders, err = resolveEncoders(len(record), c.names, c.options)
+			if err != nil {
+				return Sample{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			if c.options.Header {
+				continue
+			}
+		}
+
+		input, ok, err := parseColumns(record, c.inputs, c.names, c.encoders, c.isMissing, c.options)
+		if err != nil {
+			return Sample{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !ok {
+			continue
+		}
+		output, ok, err := parseColumns(record, c.outputs, c.names, c.encoders, c.isMissing, c.options)
+		if err != nil {
+			return Sample{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !ok {
+			continue
+		}
+
+		return Sample{Input: input, Output: output}, nil
+	}
+}
+
+// Closes the underlying reader if needed.
+func (c *csvIterator) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// Resolves the indices of the input and output columns from the given options.
+func selectColumns(count int, names []string, options CSVOptions) ([]int, []int, error) {
+	skip, err := resolveColumns(count, names, options.SkipColumns, options.SkipIndices)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs, err := resolveColumns(count, names, options.OutputColumns, options.OutputIndices)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := resolveColumns(count, names, options.InputColumns, options.InputIndices)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	skipped := make(map[int]bool, len(skip))
+	for _, i := range skip {
+		skipped[i] = true
+	}
+
+	if len(outputs) == 0 && !options.InputsOnly {
+		for i := count - 1; i >= 0; i-- {
+			if !skipped[i] {
+				outputs = []int{i}
+				break
+			}
+		}
+	}
+	if len(inputs) == 0 {
+		isOutput := make(map[int]bool, len(outputs))
+		for _, i := range outputs {
+			isOutput[i] = true
+		}
+		for i := 0; i < count; i++ {
+			if !skipped[i] && !isOutput[i] {
+				inputs = append(inputs, i)
+			}
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, nil, errors.New("at least one input column is required")
+	}
+	if len(outputs) == 0 && !options.InputsOnly {
+		return nil, nil, errors.New("at least one output column is required")
+	}
+	return inputs, outputs, nil
+}
+
+// Resolves the given column names and indices into a slice of indices.
+func resolveColumns(count int, names []string, columns []string, indices []int) ([]int, error) {
+	var resolved []int
+	for _, column := range columns {
+		if names == nil {
+			return nil, fmt.Errorf("column %q selected by name, but the file has no header", column)
+		}
+		index := -1
+		for i, name := range names {
+			if name == column {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("column %q not found in header", column)
+		}
+		resolved = append(resolved, index)
+	}
+	for _, index := range indices {
+		if index < 0 || index >= count {
+			return nil, fmt.Errorf("column index %d out of range, the file has %d columns", index, count)
+		}
+		resolved = append(resolved, index)
+	}
+	return resolved, nil
+}
+
+// Resolves the columns of the encoders given by the options.
+func resolveEncoders(count int, names []string, options CSVOptions) (map[int]CategoricalEncoder, error) {
+	encoders := make(map[int]CategoricalEncoder, len(options.Encoders)+len(options.EncoderIndices))
+	for column, encoder := range options.Encoders {
+		indices, err := resolveColumns(count, names, []string{column}, nil)
+		if err != nil {
+			return nil, err
+		}
+		encoders[indices[0]] = encoder
+	}
+	for index, encoder := range options.EncoderIndices {
+		indices, err := resolveColumns(count, names, nil, []int{index})
+		if err != nil {
+			return nil, err
+		}
+		encoders[indices[0]] = encoder
+	}
+	return encoders, nil
+}
+
+// Parses the given columns of a record, encoding categorical columns using their encoders.
+// Returns false if the record should be skipped because of a missing value.
+func parseColumns(record []string, columns []int, names []string, encoders map[int]CategoricalEncoder, isMissing map[string]bool, options CSVOptions) ([]float64, bool, error) {
+	values := make([]float64, 0, len(columns))
+	for _, column := range columns {
+		raw := record[column]
+		encoder, categorical := encoders[column]
+
+		if isMissing[raw] {
+			switch options.Missing {
+			case MissingSkip:
+				return nil, false, nil
+			case MissingFill:
+				dimension := 1
+				if categorical {
+					dimension = encoder.Dimension()
+				}
+				for i := 0; i < dimension; i++ {
+					values = append(values, options.FillValue)
+				}
+				continue
+			default:
+				return nil, false, fmt.Errorf("missing value in column %s", columnName(column, names))
+			}
+		}
+
+		if categorical {
+			encoded, err := encoder.Encode(raw)
+			if err != nil {
+				return nil, false, fmt.Errorf("column %s: %w", columnName(column, names), err)
+			}
+			values = append(values, encoded...)
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("column %s: %w", columnName(column, names), err)
+		}
+		values = append(values, value)
+	}
+	return values, true, nil
+}
+
+// Returns a human readable name of the given column.
+func columnName(column int, names []string) string {
+	if names != nil {
+		return strconv.Quote(names[column])
+	}
+	return strconv.Itoa(column)
+}
+
+// Fits the encoders given by the options to the values of their columns read from the given CSV reader.
+// Missing values are not passed to the encoders.
+func FitCSVEncoders(r io.Reader, options CSVOptions) error {
+	reader := csv.NewReader(r)
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+	reader.Comment = options.Comment
+	isMissing := missingValues(options)
+
+	var encoders map[int]CategoricalEncoder
+	var names []string
+	values := make(map[int][]string)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if first {
+			if options.Header {
+				names = append([]string(nil), record...)
+			}
+			if encoders, err = resolveEncoders(len(record), names, options); err != nil {
+				line, _ := reader.FieldPos(0)
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if options.Header {
+				continue
+			}
+		}
+
+		for column := range encoders {
+			if !isMissing[record[column]] {
+				values[column] = append(values[column], record[column])
+			}
+		}
+	}
+
+	for column, encoder := range encoders {
+		encoder.Fit(values[column])
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package feedforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Represents an encoder which maps categorical string values into floating point vectors.
+// Fit collects the categories from the given values, which are sorted so that the encoding does not depend on the
+// order of the values. Dimension returns the length of the encoded vectors.
+// Encode does not modify the encoder, so a fitted encoder can be used concurrently.
+// Encoders of this package can be encoded using encoding/json and restored using UnmarshalEncoder, so that they can be
+// stored alongside a model.
+type CategoricalEncoder interface {
+	Fit(values []string)
+	Encode(value string) ([]float64, error)
+	Categories() []string
+	Dimension() int
+}
+
+// Returns the sorted distinct values of the given slice.
+func distinct(values []string) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			categories = append(categories, value)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Checks that the given categories are distinct, as every category must map onto a single index.
+func checkDistinct(categories []string) error {
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if seen[category] {
+			return fmt.Errorf("duplicate category %q", category)
+		}
+		seen[category] = true
+	}
+	return nil
+}
+
+// Returns a map from every category to its index.
+func indexCategories(categories []string) map[string]int {
+	index := make(map[string]int, len(categories))
+	for i, category := range categories {
+		index[category] = i
+	}
+	return index
+}
+
+// Type holding the categories of a one-hot encoder.
+type oneHotEncoder struct {
+	categories    []string
+	index         map[string]int
+	ignoreUnknown bool
+}
+
+// Constructor of an encoder which maps every category onto a vector with a single 1 at the index of the category.
+// If ignoreUnknown is true, categories not seen during Fit are encoded as a vector of zeros, otherwise they produce
+// an error.
+func NewOneHotEncoder(ignoreUnknown bool) CategoricalEncoder {
+	return &oneHotEncoder{ignoreUnknown: ignoreUnknown}
+}
+
+// Collects the categories from the given values.
+func (o *oneHotEncoder) Fit(values []string) {
+	o.categories = distinct(values)
+	o.index = indexCategories(o.categories)
+}
+
+// Encodes the given value into a one-hot vector.
+func (o *oneHotEncoder) Encode(value string) ([]float64, error) {
+	if o.index == nil {
+		return nil, errors.New("this instance of encoder has not been fitted yet")
+	}
+	encoded := make([]float64, len(o.categories))
+	i, ok := o.index[value]
+	if !ok {
+		if o.ignoreUnknown {
+			return encoded, nil
+		}
+		return nil, fmt.Errorf("unknown category %q", value)
+	}
+	encoded[i] = 1
+	return encoded, nil
+}
+
+// Gets the categories of the encoder, in order of their index.
+func (o *oneHotEncoder) Categories() []string {
+	return o.categories
+}
+
+// Gets the length of the encoded vectors, which is equal to the number of categories.
+func (o *oneHotEncoder) Dimension() int {
+	return len(o.categories)
+}
+
+// Type holding the serialized form of the encoders of this package.
+type encoderJSON struct {
+	Kind          string   `json:"type"`
+	Categories    []string `json:"categories"`
+	IgnoreUnknown bool     `json:"ignore_unknown,omitempty"`
+	Fixed         bool     `json:"fixed,omitempty"`
+}
+
+// Encodes the categories of the encoder into JSON.
+func (o *oneHotEncoder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoderJSON{Kind: "onehot", Categories: o.categories, IgnoreUnknown: o.ignoreUnknown})
+}
+
+// Type holding the categories of an ordinal encoder.
+type ordinalEncoder struct {
+	categories []string
+	index      map[string]int
+	fixed      bool
+}
+
+// Constructor of an encoder which maps every category onto a single value equal to the index of the category.
+// Categories are ordered lexicographically unless given explicitly, in which case Fit does not change them.
+// Returns an error if the given categories are not distinct.
+func NewOrdinalEncoder(categories ...string) (CategoricalEncoder, error) {
+	encoder := &ordinalEncoder{}
+	if len(categories) > 0 {
+		if err := checkDistinct(categories); err != nil {
+			return nil, err
+		}
+		encoder.categories = append([]string(nil), categories...)
+		encoder.index = indexCategories(encoder.categories)
+		encoder.fixed = true
+	}
+	return encoder, nil
+}
+
+// Collects the categories from the given values, unless they were given explicitly.
+func (o *ordinalEncoder) Fit(values []string) {
+	if o.fixed {
+		return
+	}
+	o.categories = distinct(values)
+	o.index = indexCategories(o.categories)
+}
+
+// Encodes the given value into a vector holding the index of its category.
+func (o *ordinalEncoder) Encode(value string) ([]float64, error) {
+	if o.index == nil {
+		return nil, errors.New("this instance of encoder has not been fitted yet")
+	}
+	i, ok := o.index[value]
+	if !ok {
+		return nil, fmt.Errorf("unknown category %q", value)
+	}
+	return []float64{float64(i)}, nil
+}
+
+// Gets the categories of the encoder, in order of their index.
+func (o *ordinalEncoder) Categories() []string {
+	return o.categories
+}
+
+// Gets the length of the encoded vectors, which is always 1.
+func (o *ordinalEncoder) Dimension() int {
+	return 1
+}
+
+// Encodes the categories of the encoder into JSON.
+func (o *ordinalEncoder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoderJSON{Kind: "ordinal", Categories: o.categories, Fixed: o.fixed})
+}
+
+// Restores an encoder from JSON produced by marshalling an encoder of this package.
+func UnmarshalEncoder(data []byte) (CategoricalEncoder, error) {
+	var decoded encoderJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	if err := checkDistinct(decoded.Categories); err != nil {
+		return nil, err
+	}
+	var index map[string]int
+	if decoded.Categories != nil {
+		index = indexCategories(decoded.Categories)
+	}
+	switch decoded.Kind {
+	case "onehot":
+		return &oneHotEncoder{categories: decoded.Categories, index: index, ignoreUnknown: decoded.IgnoreUnknown}, nil
+	case "ordinal":
+		return &ordinalEncoder{categories: decoded.Categories, index: index, fixed: decoded.Fixed}, nil
+	default:
+		return nil, fmt.Errorf("unknown encoder type %q", decoded.Kind)
+	}
+}
+
+// Type which maps class labels onto one-hot output vectors and decodes network outputs back into class labels.
+// Decoding follows the conventions of the classification metrics: a single output is treated as the probability of
+// the second class of a binary problem, multiple outputs are treated as scores of the corresponding classes.
+// Encoding and decoding do not modify the encoder, so a fitted encoder can be used concurrently.
+// LabelEncoder implements CategoricalEncoder, so it can encode the column of class labels of a CSV file.
+type LabelEncoder struct {
+	Classes []string `json:"classes"`
+	index   map[string]int
+}
+
+// Constructor of a label encoder, classes can be given explicitly or collected from labels using Fit.
+// Returns an error if the given classes are not distinct.
+func NewLabelEncoder(classes ...string) (*LabelEncoder, error) {
+	encoder := &LabelEncoder{}
+	if len(classes) > 0 {
+		if err := checkDistinct(classes); err != nil {
+			return nil, err
+		}
+		encoder.Classes = append([]string(nil), classes...)
+		encoder.index = indexCategories(encoder.Classes)
+	}
+	return encoder, nil
+}
+
+// Collects the sorted distinct classes from the given labels.
+func (l *LabelEncoder) Fit(labels []string) {
+	l.Classes = distinct(labels)
+	l.index = indexCategories(l.Classes)
+}
+
+// Decodes an encoder encoded using encoding/json, indexing its classes.
+func (l *LabelEncoder) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		Classes []string `json:"classes"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := checkDistinct(decoded.Classes); err != nil {
+		return err
+	}
+	l.Classes = decoded.Classes
+	l.index = indexCategories(decoded.Classes)
+	return nil
+}
+
+// Gets the index of the given class.
+// Classes assigned directly to the Classes field are not indexed, in which case they are searched.
+func (l *LabelEncoder) classIndex(label string) (int, bool) {
+	if l.index != nil {
+		i, ok := l.index[label]
+		return i, ok
+	}
+	for i, class := range l.Classes {
+		if class == label {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Gets the classes of the encoder, in order of their index.
+func (l *LabelEncoder) Categories() []string {
+	return l.Classes
+}
+
+// Gets the length of the encoded vectors, which is equal to the number of classes.
+func (l *LabelEncoder) Dimension() int {
+	return len(l.Classes)
+}
+
+// Encodes the given label into a one-hot vector.
+func (l *LabelEncoder) Encode(label string) ([]float64, error) {
+	if len(l.Classes) == 0 {
+		return nil, errors.New("this instance of LabelEncoder has not been fitted yet")
+	}
+	i, ok := l.classIndex(label)
+	if !ok {
+		return nil, fmt.Errorf("unknown class %q", label)
+	}
+	encoded := make([]float64, len(l.Classes))
+	encoded[i] = 1
+	return encoded, nil
+}
+
+// Creates samples from the given inputs and class labels, encoding every label into a one-hot output.
+func (l *LabelEncoder) Samples(inputs [][]float64, labels []string) ([]Sample, error) {
+	if len(inputs) != len(labels) {
+		return nil, errors.New("number of inputs does not match the number of labels")
+	}
+	samples := make([]Sample, len(inputs))
+	for i := range inputs {
+		output, err := l.Encode(labels[i])
+		if err != nil {
+			return nil, err
+		}
+		samples[i] = Sample{Input: inputs[i], Output: output}
+	}
+	return samples, nil
+}
+
+// Checks whether the given output can be decoded by this encoder.
+func (l *LabelEncoder) check(output []float64) error {
+	if len(l.Classes) == 0 {
+		return errors.New("this instance of LabelEncoder has not been fitted yet")
+	}
+	if len(output) != len(l.Classes) && !(len(output) == 1 && len(l.Classes) == 2) {
+		return errors.New("given output is not of expected dimension")
+	}
+	return nil
+}
+
+// Decodes the given network output into the class label with the highest score.
+func (l *LabelEncoder) Decode(output []float64) (string, error) {
+	if err := l.check(output); err != nil {
+		return "", err
+	}
+	return l.Classes[classOf(output)], nil
+}
+
+// Decodes the given network output into class probabilities.
+// Scores are clipped to be non-negative and normalized to sum to one, if all scores are zero every class is given
+// equal probability.
+func (l *LabelEncoder) Probabilities(output []float64) (map[string]float64, error) {
+	if err := l.check(output); err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, len(l.Classes))
+	total := 0.
+	for c := range l.Classes {
+		scores[c] = math.Max(classProbability(output, c), 0)
+		total += scores[c]
+	}
+
+	probabilities := make(map[string]float64, len(l.Classes))
+	for c, class := range l.Classes {
+		if total == 0 {
+			probabilities[class] = 1 / float64(len(l.Classes))
+		} else {
+			probabilities[class] = scores[c] / total
+		}
+	}
+	return probabilities, nil
+}
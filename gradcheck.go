@@ -0,0 +1,116 @@
+package feedforward
+
+import (
+	"errors"
+	"math"
+)
+
+// Type holding the largest relative errors between analytic and numerical gradients of a single layer.
+// The relative error of a parameter is |analytic - numerical| / max(|analytic|, |numerical|), which is 0 if both
+// gradients are 0. Errors below 1e-7 indicate a correct implementation, errors above 1e-2 almost certainly indicate a
+// bug, keeping in mind that kinks such as ReLU at 0 produce isolated large errors.
+type GradientError struct {
+	Layer            int
+	WeightError      float64
+	BiasError        float64
+	MaxRelativeError float64
+}
+
+// Compares the gradients computed by the backward pass of the network against central finite differences
+// (f(x + epsilon) - f(x - epsilon)) / (2 * epsilon) for every weight and bias, and returns the largest relative error
+// of every layer.
+// Gradients are computed of the objective minimized by backpropagation, half the squared error summed over outputs and
+// averaged over the given samples, so that custom activation functions and layers can be validated.
+// The network must be fitted or have its parameters loaded, the parameters are restored after checking.
+func GradientCheck(network *Network, samples []Sample, epsilon float64) ([]GradientError, error) {
+	if !network.isFitted {
+		return nil, errors.New("this instance of Network has not been fitted yet")
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("no samples given")
+	}
+	if epsilon <= 0 {
+		return nil, errors.New("epsilon must be positive")
+	}
+	for _, sample := range samples {
+		if len(sample.Input) != network.neurons[0] || len(sample.Output) != network.neurons[len(network.neurons)-1] {
+			return nil, errors.New("given sample is not of expected dimension")
+		}
+	}
+
+	weightGradients, biasGradients := network.analyticGradients(samples)
+	objective := func() float64 {
+		total := 0.
+		for _, sample := range samples {
+			output := network.predict(sample.Input)
+			for i := range output {
+				diff := sample.Output[i] - output[i]
+				total += 0.5 * diff * diff
+			}
+		}
+		return total / float64(len(samples))
+	}
+	numerical := func(parameter *float64) float64 {
+		original := *parameter
+		*parameter = original + epsilon
+		plus := objective()
+		*parameter = original - epsilon
+		minus := objective()
+		*parameter = original
+		return (plus - minus) / (2 * epsilon)
+	}
+
+	results := make([]GradientError, len(network.layers))
+	for k, l := range network.layers {
+		results[k].Layer = k
+		weights := l.getWeights()
+		for i := range weights {
+			for j := range weights[i] {
+				e := relativeError(weightGradients[k][i][j], numerical(&weights[i][j]))
+				results[k].WeightError = math.Max(results[k].WeightError, e)
+			}
+		}
+		biases := l.getBiases()
+		for i := range biases {
+			e := relativeError(biasGradients[k][i], numerical(&biases[i]))
+			results[k].BiasError = math.Max(results[k].BiasError, e)
+		}
+		results[k].MaxRelativeError = math.Max(results[k].WeightError, results[k].BiasError)
+	}
+	return results, nil
+}
+
+// Computes the gradients of half the squared error averaged over the given samples using the backward pass, without
+// updating the parameters of the network.
+func (n *Network) analyticGradients(samples []Sample) ([][][]float64, [][]float64) {
+	weights := constructWeights(n.neurons)
+	biases := constructBiases(n.neurons)
+	scale := 1 / float64(len(samples))
+	for _, sample := range samples {
+		deltas := n.backward(sample)
+		for k := range n.layers {
+			prevLayerOutput := sample.Input
+			if k != 0 {
+				prevLayerOutput = n.layers[k-1].getOutputCache()
+			}
+			for i := range weights[k] {
+				for j := range weights[k][i] {
+					weights[k][i][j] -= deltas[k][j] * prevLayerOutput[i] * scale
+				}
+			}
+			for i := range biases[k] {
+				biases[k][i] -= deltas[k][i] * scale
+			}
+		}
+	}
+	return weights, biases
+}
+
+// Computes the relative error between two gradients.
+func relativeError(analytic, numerical float64) float64 {
+	scale := math.Max(math.Abs(analytic), math.Abs(numerical))
+	if scale == 0 {
+		return 0
+	}
+	return math.Abs(analytic-numerical) / scale
+}
@@ -0,0 +1,225 @@
+package feedforward
+
+import (
+	"io"
+	"math"
+	"sync"
+)
+
+// Type holding a read-only copy of the parameters of a single layer of a network, along with the mean gradients and
+// the relative magnitude of the update of the last completed epoch.
+// Weights are indexed as Weights[i][j], i being a neuron of the previous layer and j a neuron of this layer.
+// Gradients are nil and UpdateRatio is math.NaN until the network completes an epoch. Gradients are recovered from the
+// update of the epoch, so they are math.NaN if the learning rate is 0.
+// UpdateRatio is the euclidean norm of the update of the weights divided by the euclidean norm of the weights, or
+// math.NaN if all weights are zero. Values far below 1e-3 usually indicate vanishing gradients, values far above
+// indicate a too large learning rate.
+type LayerSnapshot struct {
+	Layer           int
+	Activation      string
+	Weights         [][]float64
+	Biases          []float64
+	WeightGradients [][]float64
+	BiasGradients   []float64
+	UpdateRatio     float64
+}
+
+// Type holding statistics of the outputs of a single layer over a set of samples.
+// DeadFraction is the fraction of neurons whose output is zero for every sample, which for ReLU indicates dead
+// neurons.
+type ActivationStatistic struct {
+	Layer        int
+	Mean         float64
+	Std          float64
+	DeadFraction float64
+}
+
+// Represents a MetricStatistic which additionally gives access to the state of every layer of the network.
+// GetLayers returns a snapshot of the parameters and gradients of every layer.
+// GetActivations returns statistics of the outputs of every layer over the training samples.
+// Both are computed lazily and at most once.
+type InspectionStatistic interface {
+	MetricStatistic
+	GetLayers() []LayerSnapshot
+	GetActivations() []ActivationStatistic
+}
+
+// Implementation of InspectionStatistic which computes the layer snapshots and activation statistics on demand.
+type inspectionStatistic struct {
+	MetricStatistic
+	layersOnce      sync.Once
+	layersFunc      func() []LayerSnapshot
+	layers          []LayerSnapshot
+	activationsOnce sync.Once
+	activationsFunc func() []ActivationStatistic
+	activations     []ActivationStatistic
+}
+
+// Constructor for a new inspectionStatistic which extends the given statistic with layer snapshots and activation
+// statistics computed by the given functions.
+func NewInspectionStatistic(statistic MetricStatistic, layers func() []LayerSnapshot, activations func() []ActivationStatistic) InspectionStatistic {
+	return &inspectionStatistic{MetricStatistic: statistic, layersFunc: layers, activationsFunc: activations}
+}
+
+// Gets the layer snapshots, computing them on first call.
+func (i *inspectionStatistic) GetLayers() []LayerSnapshot {
+	i.layersOnce.Do(func() { i.layers = i.layersFunc() })
+	return i.layers
+}
+
+// Gets the activation statistics, computing them on first call.
+func (i *inspectionStatistic) GetActivations() []ActivationStatistic {
+	i.activationsOnce.Do(func() { i.activations = i.activationsFunc() })
+	return i.activations
+}
+
+// Type representing a function which is called with a snapshot of a single layer.
+type LayerCallback func(statistic IterationStatistic, layer LayerSnapshot)
+
+// Observer type which calls a LayerCallback for selected layers of the network on every Iteration.
+type layerObserver struct {
+	callback LayerCallback
+	layers   []int
+}
+
+// Constructor for generating a new layerObserver which calls the callback for the layers with the given indices, or
+// for every layer if no indices are given. Statistics which do not implement InspectionStatistic are ignored.
+func NewLayerObserver(callback LayerCallback, layers ...int) ModelObserver {
+	return &layerObserver{callback: callback, layers: layers}
+}
+
+// Calls the callback for every selected layer.
+func (l *layerObserver) Update(statistic IterationStatistic) {
+	inspection, ok := statistic.(InspectionStatistic)
+	if !ok {
+		return
+	}
+	snapshots := inspection.GetLayers()
+	if len(l.layers) == 0 {
+		for _, snapshot := range snapshots {
+			l.callback(statistic, snapshot)
+		}
+		return
+	}
+	for _, k := range l.layers {
+		if k >= 0 && k < len(snapshots) {
+			l.callback(statistic, snapshots[k])
+		}
+	}
+}
+
+// Gets the number of layers of the network, not counting the input layer.
+func (n *Network) LayerCount() int {
+	return len(n.layers)
+}
+
+// Creates a snapshot of the parameters and gradients of every layer of the network.
+// The returned slices are copies, modifying them does not affect the network.
+func (n *Network) Inspect() []LayerSnapshot {
+	weights, biases := n.copyParameters()
+	snapshots := make([]LayerSnapshot, len(n.layers))
+	for k := range n.layers {
+		snapshots[k] = LayerSnapshot{
+			Layer:       k,
+			Activation:  n.activations[k].Name,
+			Weights:     weights[k],
+			Biases:      biases[k],
+			UpdateRatio: math.NaN(),
+		}
+		if n.weightGradients != nil {
+			snapshots[k].WeightGradients = copyMatrix(n.weightGradients[k])
+			snapshots[k].BiasGradients = append([]float64(nil), n.biasGradients[k]...)
+			snapshots[k].UpdateRatio = n.updateRatios[k]
+		}
+	}
+	return snapshots
+}
+
+// Creates a deep copy of the given matrix.
+func copyMatrix(matrix [][]float64) [][]float64 {
+	copied := make([][]float64, len(matrix))
+	for i := range matrix {
+		copied[i] = append([]float64(nil), matrix[i]...)
+	}
+	return copied
+}
+
+// Computes statistics of the outputs of every layer over the given samples.
+func (n *Network) ActivationStatistics(samples []Sample) []ActivationStatistic {
+	statistics, _ := n.activationStatistics(NewSliceSource(samples))
+	return statistics
+}
+
+// Computes statistics of the outputs of every layer over the samples of the given source.
+// If the source could not be read, all statistics are math.NaN and the error is returned.
+func (n *Network) activationStatistics(source DataSource) ([]ActivationStatistic, error) {
+	sums := make([]float64, len(n.layers))
+	squares := make([]float64, len(n.layers))
+	alive := make([][]bool, len(n.layers))
+	for k := range n.layers {
+		alive[k] = make([]bool, n.neurons[k+1])
+	}
+
+	iterator, err := source.Open()
+	if err != nil {
+		return n.undefinedActivations(), err
+	}
+	defer iterator.Close()
+
+	count := 0
+	for {
+		batch, err := nextBatch(iterator, sourceBatchSize)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n.undefinedActivations(), err
+		}
+
+		for _, sample := range batch {
+			output := sample.Input
+			for k, l := range n.layers {
+				output = l.computeOutput(output)
+				for i, value := range output {
+					sums[k] += value
+					squares[k] += value * value
+					if value != 0 {
+						alive[k][i] = true
+					}
+				}
+			}
+			count++
+		}
+	}
+
+	statistics := make([]ActivationStatistic, len(n.layers))
+	for k := range n.layers {
+		values := float64(count * n.neurons[k+1])
+		mean := sums[k] / values
+		dead := 0
+		for _, isAlive := range alive[k] {
+			if !isAlive {
+				dead++
+			}
+		}
+		statistics[k] = ActivationStatistic{
+			Layer:        k,
+			Mean:         mean,
+			Std:          math.Sqrt(math.Max(squares[k]/values-mean*mean, 0)),
+			DeadFraction: float64(dead) / float64(len(alive[k])),
+		}
+		if count == 0 {
+			statistics[k].DeadFraction = math.NaN()
+		}
+	}
+	return statistics, nil
+}
+
+// Creates activation statistics of every layer with all values set to math.NaN.
+func (n *Network) undefinedActivations() []ActivationStatistic {
+	statistics := make([]ActivationStatistic, len(n.layers))
+	for k := range statistics {
+		statistics[k] = ActivationStatistic{Layer: k, Mean: math.NaN(), Std: math.NaN(), DeadFraction: math.NaN()}
+	}
+	return statistics
+}
@@ -0,0 +1,227 @@
+package feedforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Type holding the parts of a Keras model serialized by model.to_json() needed for importing a network.
+type kerasModel struct {
+	ClassName string          `json:"class_name"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// Type holding a serialized Keras layer.
+type kerasLayer struct {
+	ClassName string      `json:"class_name"`
+	Config    kerasConfig `json:"config"`
+}
+
+// Type holding the configuration fields of the supported Keras layers.
+// Inputs are declared by batch_input_shape in Keras 2 and by batch_shape in Keras 3.
+type kerasConfig struct {
+	Name            string          `json:"name"`
+	Units           int             `json:"units"`
+	Activation      json.RawMessage `json:"activation"`
+	UseBias         *bool           `json:"use_bias"`
+	BatchInputShape []*int          `json:"batch_input_shape"`
+	BatchShape      []*int          `json:"batch_shape"`
+}
+
+// Reads a network from a Keras Sequential model consisting of Dense layers.
+//
+// The architecture is the JSON returned by model.to_json(). The weights are a JSON array holding the arrays returned
+// by model.get_weights() in order, which can be written by
+//
+//	json.dump([w.tolist() for w in model.get_weights()], file)
+//
+// that is the kernel of every Dense layer, of shape [inputs, units], followed by its bias unless use_bias is false.
+// InputLayer and Dropout layers are allowed, Activation layers apply to a preceding Dense layer with a linear
+// activation. Sigmoid, tanh, relu and linear activations are supported, other layers and activations result in an
+// error.
+// The imported network has a learning rate of 0, which has to be set using SetLearningRate before training it further.
+func ImportKeras(architecture io.Reader, weights io.Reader) (*Network, error) {
+	var model kerasModel
+	if err := json.NewDecoder(architecture).Decode(&model); err != nil {
+		return nil, fmt.Errorf("invalid Keras architecture: %w", err)
+	}
+	if model.ClassName != "Sequential" {
+		return nil, fmt.Errorf("only Sequential Keras models are supported, found %q", model.ClassName)
+	}
+
+	// Keras 1 serializes the layers as the configuration itself
+	var layers []kerasLayer
+	var config struct {
+		Layers []kerasLayer `json:"layers"`
+	}
+	if err := json.Unmarshal(model.Config, &layers); err != nil {
+		if err := json.Unmarshal(model.Config, &config); err != nil {
+			return nil, fmt.Errorf("invalid Keras architecture: %w", err)
+		}
+		layers = config.Layers
+	}
+
+	var arrays []json.RawMessage
+	if err := json.NewDecoder(weights).Decode(&arrays); err != nil {
+		return nil, fmt.Errorf("invalid Keras weights, expected a JSON array of arrays: %w", err)
+	}
+
+	total := len(arrays)
+	neurons := []int{0}
+	var activations []ActivationFunction
+	var linear []bool
+	var kernels [][][]float64
+	var biases [][]float64
+	for i, l := range layers {
+		name := l.Config.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		if shape := l.Config.inputShape(); shape != nil {
+			if len(kernels) > 0 {
+				return nil, fmt.Errorf("layer %q declares an input shape after the first Dense layer", name)
+			}
+			if len(shape) != 2 || shape[1] == nil || *shape[1] <= 0 {
+				return nil, fmt.Errorf("layer %q has input shape %s, only vectors of known size are supported", name, formatKerasShape(shape))
+			}
+			neurons[0] = *shape[1]
+		}
+
+		switch l.ClassName {
+		case "InputLayer", "Dropout":
+		case "Dense":
+			activation, isLinear, err := kerasActivation(l.Config.Activation)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", name, err)
+			}
+			if l.Config.Units <= 0 {
+				return nil, fmt.Errorf("layer %q has %d units", name, l.Config.Units)
+			}
+
+			if len(arrays) == 0 {
+				return nil, fmt.Errorf("weights are missing the kernel of layer %q", name)
+			}
+			var kernel [][]float64
+			if err := json.Unmarshal(arrays[0], &kernel); err != nil {
+				return nil, fmt.Errorf("kernel of layer %q is not a matrix: %w", name, err)
+			}
+			arrays = arrays[1:]
+			if neurons[0] == 0 && len(kernels) == 0 {
+				neurons[0] = len(kernel)
+			}
+			if len(kernel) != neurons[len(neurons)-1] {
+				return nil, fmt.Errorf("kernel of layer %q has %d rows, expected %d", name, len(kernel), neurons[len(neurons)-1])
+			}
+			for _, row := range kernel {
+				if len(row) != l.Config.Units {
+					return nil, fmt.Errorf("kernel of layer %q has a row of %d values, expected %d units", name, len(row), l.Config.Units)
+				}
+			}
+
+			bias := make([]float64, l.Config.Units)
+			if l.Config.UseBias == nil || *l.Config.UseBias {
+				if len(arrays) == 0 {
+					return nil, fmt.Errorf("weights are missing the bias of layer %q", name)
+				}
+				if err := json.Unmarshal(arrays[0], &bias); err != nil {
+					return nil, fmt.Errorf("bias of layer %q is not a vector: %w", name, err)
+				}
+				arrays = arrays[1:]
+				if len(bias) != l.Config.Units {
+					return nil, fmt.Errorf("bias of layer %q has %d values, expected %d units", name, len(bias), l.Config.Units)
+				}
+			}
+
+			neurons = append(neurons, l.Config.Units)
+			activations = append(activations, activation)
+			linear = append(linear, isLinear)
+			kernels = append(kernels, kernel)
+			biases = append(biases, bias)
+		case "Activation":
+			activation, isLinear, err := kerasActivation(l.Config.Activation)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", name, err)
+			}
+			if isLinear {
+				continue
+			}
+			if len(linear) == 0 || !linear[len(linear)-1] {
+				return nil, fmt.Errorf("layer %q of class Activation must follow a Dense layer with a linear activation", name)
+			}
+			activations[len(activations)-1] = activation
+			linear[len(linear)-1] = false
+		default:
+			return nil, fmt.Errorf("layer %q of class %s is not supported, only Dense, Activation, Dropout and InputLayer are", name, l.ClassName)
+		}
+	}
+
+	if len(kernels) == 0 {
+		return nil, errors.New("Keras model has no Dense layers")
+	}
+	if len(arrays) > 0 {
+		return nil, fmt.Errorf("weights hold %d arrays, the Dense layers of the model use %d", total, total-len(arrays))
+	}
+	return newFittedNetwork(neurons, activations, kernels, biases, 0), nil
+}
+
+// Loads a network from a Keras architecture file and a weights file, as described by ImportKeras.
+func LoadKeras(architecturePath, weightsPath string) (*Network, error) {
+	architecture, err := os.Open(architecturePath)
+	if err != nil {
+		return nil, err
+	}
+	defer architecture.Close()
+	weights, err := os.Open(weightsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer weights.Close()
+	return ImportKeras(architecture, weights)
+}
+
+// Gets the declared input shape of the layer, nil if the layer does not declare one.
+func (c kerasConfig) inputShape() []*int {
+	if c.BatchShape != nil {
+		return c.BatchShape
+	}
+	return c.BatchInputShape
+}
+
+// Maps a serialized Keras activation onto the equivalent built-in activation function.
+// Linear activations are reported separately, so that a following Activation layer can replace them.
+func kerasActivation(encoded json.RawMessage) (ActivationFunction, bool, error) {
+	var name string
+	if len(encoded) > 0 {
+		if err := json.Unmarshal(encoded, &name); err != nil {
+			return ActivationFunction{}, false, fmt.Errorf("activation %s is not supported, only activations given by name are", encoded)
+		}
+	}
+	if name == "" || name == "linear" {
+		return Linear(), true, nil
+	}
+	// the names of the built-in activation functions match the names used by Keras
+	activation, err := ActivationByName(name)
+	if err != nil {
+		return ActivationFunction{}, false, fmt.Errorf("activation %q is not supported, only sigmoid, tanh, relu and linear are", name)
+	}
+	return activation, false, nil
+}
+
+// Formats a Keras shape, unknown dimensions being null.
+func formatKerasShape(shape []*int) string {
+	encoded := "["
+	for i, dim := range shape {
+		if i > 0 {
+			encoded += ", "
+		}
+		if dim == nil {
+			encoded += "null"
+		} else {
+			encoded += fmt.Sprint(*dim)
+		}
+	}
+	return encoded + "]"
+}
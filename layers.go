@@ -4,6 +4,7 @@ package feedforward
 type layer interface {
 	initialize(Initializer)
 	processInput([]float64) []float64
+	computeOutput([]float64) []float64
 	getOutputCache() []float64
 	processError([]float64) []float64
 	getWeights() [][]float64
@@ -31,15 +32,21 @@ func (l *baseLayer) initialize(initializer Initializer) {
 }
 
 // Computes output of the entire layer for the given input and caches the output before returning to caller.
-// The output is computed concurrently for each neuron through goroutines, synchronized through a WaitGroup.
 func (l *baseLayer) processInput(input []float64) []float64 {
+	output := l.computeOutput(input)
+	l.outputCache = output
+	return output
+}
+
+// Computes output of the entire layer for the given input without caching it.
+// As the layer is not modified, outputs can be computed concurrently as long as the layer is not being trained.
+func (l *baseLayer) computeOutput(input []float64) []float64 {
 	output := make([]float64, l.neurons)
 
 	for i := 0; i < l.neurons; i++ {
 		output[i] = l.activation.Value(l.net(i, input))
 	}
 
-	l.outputCache = output
 	return output
 }
 
@@ -0,0 +1,191 @@
+package feedforward
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Type holding the options of a TrainingLogger.
+// Validation is the name of a metric of the received statistics which is written as the validation score, such as a
+// loss added to the network by AddMetric along with the validation samples.
+type TrainingLogOptions struct {
+	Validation string
+}
+
+// Type holding a single record of a training log.
+// Values which are not available are NaN and are written as empty CSV fields or omitted from JSON.
+type trainingRecord struct {
+	timestamp       time.Time
+	iteration       int
+	trainScore      float64
+	validationScore float64
+	learningRate    float64
+	elapsed         time.Duration
+	metrics         map[string]float64
+}
+
+// Observer type which writes a structured record to a writer for every statistic it receives.
+// Write errors stop the logger, the first error can be retrieved using Err.
+type TrainingLogger struct {
+	options TrainingLogOptions
+	names   []string
+	write   func(logger *TrainingLogger, record trainingRecord) error
+	start   time.Time
+	err     error
+
+	csv           *csv.Writer
+	headerWritten bool
+	json          *json.Encoder
+}
+
+// Constructor for generating a new TrainingLogger which writes records as CSV with a header row.
+// Columns are timestamp, iteration, train_score, validation_score, learning_rate, elapsed_seconds and the names of
+// the metrics of the first received statistic in lexicographic order.
+func NewCSVLogger(w io.Writer, options TrainingLogOptions) *TrainingLogger {
+	logger := newTrainingLogger(options)
+	logger.csv = csv.NewWriter(w)
+	logger.write = (*TrainingLogger).writeCSV
+	return logger
+}
+
+// Constructor for generating a new TrainingLogger which writes records as JSON objects, one per line.
+// Keys are timestamp, iteration, train_score, validation_score, learning_rate, elapsed_seconds and metrics, which
+// holds an object of the values of the metrics of the statistic keyed by metric name.
+func NewJSONLinesLogger(w io.Writer, options TrainingLogOptions) *TrainingLogger {
+	logger := newTrainingLogger(options)
+	logger.json = json.NewEncoder(w)
+	logger.write = (*TrainingLogger).writeJSON
+	return logger
+}
+
+// Creates a logger without an output format.
+func newTrainingLogger(options TrainingLogOptions) *TrainingLogger {
+	return &TrainingLogger{options: options}
+}
+
+// Gets the first error encountered while writing records, nil if there was none.
+func (l *TrainingLogger) Err() error {
+	return l.err
+}
+
+// Builds a record from the given statistic and writes it.
+// The learning rate is written for statistics implementing ProgressStatistic, the validation score and metrics for
+// statistics implementing MetricStatistic.
+// The elapsed time is measured from the first Iteration of the current training.
+func (l *TrainingLogger) Update(statistic IterationStatistic) {
+	if l.err != nil {
+		return
+	}
+
+	now := time.Now()
+	if l.start.IsZero() || statistic.GetIteration() == 0 {
+		l.start = now
+	}
+
+	record := trainingRecord{
+		timestamp:       now,
+		iteration:       statistic.GetIteration(),
+		trainScore:      statistic.GetScore(),
+		validationScore: math.NaN(),
+		learningRate:    math.NaN(),
+		elapsed:         now.Sub(l.start),
+	}
+	if progress, ok := statistic.(ProgressStatistic); ok {
+		record.learningRate = progress.GetLearningRate()
+	}
+
+	// the metrics are fixed by the first statistic, as the CSV header is written only once
+	values := make(map[string]float64)
+	if metrics, ok := statistic.(MetricStatistic); ok {
+		values = metrics.GetMetrics()
+		if l.names == nil {
+			l.names = append([]string{}, metrics.MetricNames()...)
+		}
+		if value, ok := values[l.options.Validation]; ok {
+			record.validationScore = value
+		}
+	}
+	record.metrics = make(map[string]float64, len(l.names))
+	for _, name := range l.names {
+		record.metrics[name] = math.NaN()
+		if value, ok := values[name]; ok {
+			record.metrics[name] = value
+		}
+	}
+
+	l.err = l.write(l, record)
+}
+
+// Formats a value for a CSV field, NaN values are written as empty fields.
+func formatField(value float64) string {
+	if math.IsNaN(value) {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Writes the record as a CSV row, preceded by the header row on first call.
+func (l *TrainingLogger) writeCSV(record trainingRecord) error {
+	if !l.headerWritten {
+		header := append([]string{"timestamp", "iteration", "train_score", "validation_score", "learning_rate", "elapsed_seconds"}, l.names...)
+		if err := l.csv.Write(header); err != nil {
+			return err
+		}
+		l.headerWritten = true
+	}
+
+	row := []string{
+		record.timestamp.Format(time.RFC3339Nano),
+		strconv.Itoa(record.iteration),
+		formatField(record.trainScore),
+		formatField(record.validationScore),
+		formatField(record.learningRate),
+		formatField(record.elapsed.Seconds()),
+	}
+	for _, name := range l.names {
+		row = append(row, formatField(record.metrics[name]))
+	}
+	if err := l.csv.Write(row); err != nil {
+		return err
+	}
+	l.csv.Flush()
+	return l.csv.Error()
+}
+
+// Returns a pointer to the value, or nil for NaN and infinite values which cannot be represented in JSON.
+func jsonNumber(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+// Writes the record as a single line JSON object.
+func (l *TrainingLogger) writeJSON(record trainingRecord) error {
+	metrics := make(map[string]*float64, len(record.metrics))
+	for name, value := range record.metrics {
+		metrics[name] = jsonNumber(value)
+	}
+
+	return l.json.Encode(struct {
+		Timestamp       time.Time           `json:"timestamp"`
+		Iteration       int                 `json:"iteration"`
+		TrainScore      *float64            `json:"train_score,omitempty"`
+		ValidationScore *float64            `json:"validation_score,omitempty"`
+		LearningRate    *float64            `json:"learning_rate,omitempty"`
+		ElapsedSeconds  float64             `json:"elapsed_seconds"`
+		Metrics         map[string]*float64 `json:"metrics,omitempty"`
+	}{
+		Timestamp:       record.timestamp,
+		Iteration:       record.iteration,
+		TrainScore:      jsonNumber(record.trainScore),
+		ValidationScore: jsonNumber(record.validationScore),
+		LearningRate:    jsonNumber(record.learningRate),
+		ElapsedSeconds:  record.elapsed.Seconds(),
+		Metrics:         metrics,
+	})
+}
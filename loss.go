@@ -1,6 +1,7 @@
 package feedforward
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -12,7 +13,86 @@ type Predictor func([]float64) []float64
 // Represents a loss function which takes a predictor function and the sample for the predictor function to be tested against
 type LossFunction func(Predictor, []Sample) float64
 
+// Represents a metric which takes a predictor function and the samples for the predictor function to be tested against.
+// Unlike a LossFunction, a metric is not necessarily a score where lower is better, see the documentation of the
+// specific metric.
+type Metric func(Predictor, []Sample) float64
+
+// Returns a Scorer which evaluates the metric for the given predictor and samples on demand.
+func (m Metric) Scorer(predictor Predictor, samples []Sample) Scorer {
+	return func() float64 { return m(predictor, samples) }
+}
+
+// Returns a LossFunction for a metric which takes values from the interval [0, 1] where higher is better,
+// computed as 1 minus the metric value.
+// The returned LossFunction can be used in place of the loss of a Network, so that for example accuracy is monitored
+// during training.
+func (m Metric) Complement() LossFunction {
+	return func(predictor Predictor, samples []Sample) float64 { return 1 - m(predictor, samples) }
+}
+
+// Returns the built-in metric with the given name.
+// Classification metrics which require an averaging strategy use macro averaging.
+func MetricByName(name string) (Metric, error) {
+	switch name {
+	case "mse":
+		return MeanSquareError, nil
+	case "uniform_mse":
+		return UniformMeanSquareError, nil
+	case "rmse":
+		return RootMeanSquareError, nil
+	case "mae":
+		return MeanAbsoluteError, nil
+	case "mape":
+		return MeanAbsolutePercentageError, nil
+	case "median_absolute_error":
+		return MedianAbsoluteError, nil
+	case "max_error":
+		return MaxError, nil
+	case "r2":
+		return R2, nil
+	case "explained_variance":
+		return ExplainedVariance, nil
+	case "accuracy":
+		return Accuracy, nil
+	case "balanced_accuracy":
+		return BalancedAccuracy, nil
+	case "precision":
+		return PrecisionScore(Macro), nil
+	case "recall":
+		return RecallScore(Macro), nil
+	case "f1":
+		return F1Score(Macro), nil
+	case "log_loss":
+		return LogLoss, nil
+	case "roc_auc":
+		return ROCAUC, nil
+	case "pr_auc":
+		return PRAUC, nil
+	default:
+		return nil, fmt.Errorf("unknown metric %q", name)
+	}
+}
+
+// Returns a LossFunction, where lower is better, computing the built-in metric with the given name.
+// Metrics where higher is better are turned into losses using Complement, which for r2 and explained_variance, whose
+// values are at most 1, gives a loss of at least 0 as well.
+func LossByName(name string) (LossFunction, error) {
+	metric, err := MetricByName(name)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "r2", "explained_variance", "accuracy", "balanced_accuracy", "precision", "recall", "f1", "roc_auc", "pr_auc":
+		return metric.Complement(), nil
+	default:
+		return LossFunction(metric), nil
+	}
+}
+
 // MSE loss function.
+// Squared errors are summed over outputs and averaged over samples, which makes the score equal to the sum of
+// MeanSquareErrorPerOutput. See UniformMeanSquareError for a score averaged over outputs as well.
 func MeanSquareError(predictor Predictor, samples []Sample) float64 {
 	mse := 0.
 	for _, sample := range samples {
@@ -3,10 +3,10 @@ package feedforward
 // Represents machine learning model.
 // Every type that implements this interface has a Fit phase and a Predict phase.
 // Fit phase must be called before calling Predict the first time.
-// Implementations should signal if Predict is called before Fit.
+// Implementations should signal if Predict is called before Fit by returning an error.
 type Model interface {
 	Fit([]Sample)
-	Predict([]float64) []float64
+	Predict([]float64) ([]float64, error)
 }
 
 // Represents a machine learning model which can be trained without seeing all the samples.
@@ -1,12 +1,21 @@
 package feedforward
 
 import (
+	"context"
 	"errors"
+	"io"
+	"math"
 	"math/rand"
 	"sync"
 	"time"
 )
 
+// Number of samples pulled from a DataSource at a time during training and scoring.
+const sourceBatchSize = 256
+
+// Default size of the buffer used for shuffling samples of a streaming DataSource.
+const defaultShuffleBuffer = 10000
+
 // Represents a multilayer feedforward neural network trained using the online variant of SGD.
 type Network struct {
 	BaseSubject
@@ -15,8 +24,27 @@ type Network struct {
 	layers      []layer
 	initializer Initializer
 	stop        StoppingCondition
+	loss        LossFunction
 	eta         float64
+	schedule    Schedule
 	isFitted    bool
+
+	shuffleBuffer  int
+	rng            *rand.Rand
+	trainedSamples int
+
+	weightGradients [][][]float64
+	biasGradients   [][]float64
+	updateRatios    []float64
+
+	metrics []namedMetric
+}
+
+// Type holding a metric published to observers along with the samples it is computed on.
+type namedMetric struct {
+	name    string
+	metric  Metric
+	samples []Sample
 }
 
 // Constructor of a neural network.
@@ -27,8 +55,74 @@ func NewNetwork(neurons []int, activations []ActivationFunction, initializer Ini
 		layers:      constructLayers(neurons, activations),
 		initializer: initializer,
 		stop:        stop,
+		loss:        MeanSquareError,
 		eta:         eta,
+
+		shuffleBuffer: defaultShuffleBuffer,
+		rng:           newRand(),
+	}
+}
+
+// Creates a new source of random numbers seeded with the current time.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// Gets the learning rate of the network.
+// While the network is being trained using a Schedule, this is the learning rate of the current Iteration.
+func (n *Network) LearningRate() float64 {
+	return n.eta
+}
+
+// Sets the learning rate of the network, such as for further training of an imported network.
+func (n *Network) SetLearningRate(eta float64) {
+	n.eta = eta
+}
+
+// Sets the learning rate schedule used for training, nil keeps the learning rate constant.
+// The schedule is applied to the learning rate given to the network on every Iteration of Fit and PartialFit, and the
+// learning rate is restored once training stops.
+func (n *Network) SetSchedule(schedule Schedule) {
+	n.schedule = schedule
+}
+
+// Sets the stopping condition used for training.
+func (n *Network) SetStoppingCondition(stop StoppingCondition) {
+	n.stop = stop
+}
+
+// Sets the size of the buffer used for shuffling samples of a streaming DataSource before every epoch.
+// Larger buffers give a more uniform shuffle at the cost of memory. In-memory samples are always shuffled as a whole.
+func (n *Network) SetShuffleBuffer(size int) {
+	n.shuffleBuffer = size
+}
+
+// Sets the loss function used for computing the score published to observers and stopping conditions.
+// By default, the score is computed using MeanSquareError.
+// Note that the loss function does not change the objective minimized by backpropagation.
+func (n *Network) SetLoss(loss LossFunction) {
+	n.loss = loss
+}
+
+// Adds a named metric which is published to observers with every statistic, which then implements MetricStatistic.
+// The metric is computed on the given samples, or on the training samples if samples is nil.
+// Metrics are evaluated lazily and at most once per iteration, and are computed concurrently by
+// MetricStatistic.GetMetrics. Adding a metric with an existing name replaces it.
+func (n *Network) AddMetric(name string, metric Metric, samples []Sample) {
+	for i := range n.metrics {
+		if n.metrics[i].name == name {
+			n.metrics[i] = namedMetric{name: name, metric: metric, samples: samples}
+			return
+		}
 	}
+	n.metrics = append(n.metrics, namedMetric{name: name, metric: metric, samples: samples})
+}
+
+// Returns the network as a Predictor, which can be used for scoring the network with a LossFunction or a Metric.
+// The returned Predictor does not validate its input, so it should only be called with inputs of expected dimension.
+// The returned Predictor can be called concurrently, as long as the network is not being trained at the same time.
+func (n *Network) Predictor() Predictor {
+	return n.predict
 }
 
 // Constructs all layers of given specification.
@@ -75,16 +169,48 @@ func constructBiases(neurons []int) [][]float64 {
 // Initializes weights on first call, successive calls do not reinitialize weights
 // and instead use the learned parameters as a starting point.
 func (n *Network) PartialFit(samples []Sample) {
+	_ = n.PartialFitContext(context.Background(), samples)
+}
+
+// Fits model to given sample using online SGD, stopping early if the given context is done.
+// Behaves like PartialFit, but returns ctx.Err() if training was interrupted.
+func (n *Network) PartialFitContext(ctx context.Context, samples []Sample) error {
+	return n.PartialFitSource(ctx, NewSliceSource(samples))
+}
+
+// Fits model to the samples of the given source using online SGD, stopping early if the given context is done.
+// Behaves like PartialFitContext, but reads the samples lazily from the source on every epoch.
+func (n *Network) PartialFitSource(ctx context.Context, source DataSource) error {
 	if !n.isFitted {
-		n.Fit(samples)
-		return
+		return n.FitSource(ctx, source)
 	}
-	n.backpropagation(samples)
+	return n.backpropagation(ctx, source)
 }
 
 // Fits model to given sample using online SGD.
 // Initializes weights on every call, doing so concurrently on a per layer basis.
+// The given slice is not modified.
 func (n *Network) Fit(samples []Sample) {
+	_ = n.FitContext(context.Background(), samples)
+}
+
+// Fits model to given sample using online SGD, stopping early if the given context is done.
+// The context is checked between samples and between epochs, so an interrupted network is left with the parameters
+// learned up to the last completed sample and can be used for prediction or further training.
+// Returns ctx.Err() if training was interrupted, nil otherwise.
+func (n *Network) FitContext(ctx context.Context, samples []Sample) error {
+	return n.FitSource(ctx, NewSliceSource(samples))
+}
+
+// Fits model to the samples of the given source using online SGD, stopping early if the given context is done.
+// Behaves like FitContext, but reads the samples lazily from the source on every epoch, so the samples never have to
+// be held in memory as a whole.
+// Returns ctx.Err() if training was interrupted, the error of the source if reading failed, nil otherwise.
+func (n *Network) FitSource(ctx context.Context, source DataSource) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(len(n.layers))
 	for _, l := range n.layers {
@@ -94,75 +220,317 @@ func (n *Network) Fit(samples []Sample) {
 		}(l)
 	}
 	wg.Wait()
+	n.weightGradients, n.biasGradients, n.updateRatios = nil, nil, nil
 
-	n.backpropagation(samples)
+	err := n.backpropagation(ctx, source)
 	n.isFitted = true
-
+	return err
 }
 
 // Backpropagation main loop.
-// Trains the network until the StoppingCondition is met and notifies ModelObserver instances currently subscribed to the network.
-// Before starting an epoch a preprocess function is called which shuffles the samples.
-func (n *Network) backpropagation(samples []Sample) {
+// Trains the network until the StoppingCondition is met or the context is done and notifies ModelObserver instances
+// currently subscribed to the network.
+// After every epoch the magnitude of the parameter update and of the mean gradient are computed and published with
+// the next statistic, which implements InspectionStatistic and ProgressStatistic.
+func (n *Network) backpropagation(ctx context.Context, source DataSource) error {
+	eta := n.eta
+	defer func() { n.eta = eta }()
+
+	isMet := n.stop.instance()
 	iter := 0
+	gradientNorm, updateNorm := math.NaN(), math.NaN()
 	for {
-		statistics := NewIterationStatistic(iter, func() float64 { return MeanSquareError(n.forwardPass, samples) })
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if n.schedule != nil {
+			n.eta = n.schedule(eta, iter)
+		}
 
+		statistics := n.statistic(iter, source, gradientNorm, updateNorm)
 		n.NotifyObservers(statistics)
 
-		if n.stop.IsMet(statistics) {
-			break
+		if isMet(statistics) {
+			return nil
 		}
 
-		preprocess(samples)
-		n.completeEpoch(samples)
+		weights, biases := n.copyParameters()
+		count, err := n.completeEpoch(ctx, source)
+		if err != nil {
+			return err
+		}
+		updateNorm = n.updateGradients(weights, biases, count)
+		gradientNorm = gradientFromUpdate(updateNorm, n.eta, count)
 		iter++
 	}
 }
 
-// Preprocess function which shuffles the samples before every epoch.
-func preprocess(samples []Sample) {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(samples), func(i, j int) { samples[i], samples[j] = samples[j], samples[i] })
+// Type holding a statistic published by a network during training, which can be frozen for asynchronous delivery.
+type trainingStatistic struct {
+	InspectionStatistic
+	progress
+	frozen func() IterationStatistic
+}
+
+// Creates a statistic computed from a copy of the current parameters of the network.
+func (t *trainingStatistic) freeze() IterationStatistic {
+	return t.frozen()
+}
+
+// Creates the statistic of the given Iteration, holding the current learning rate and number of trained samples.
+// Its score, metrics, layer snapshots and activation statistics are computed from the network on demand.
+func (n *Network) statistic(iter int, source DataSource, gradientNorm, updateNorm float64) InspectionStatistic {
+	scorer := func() float64 { return n.evaluate(n.loss, source) }
+	statistic := NewInspectionStatistic(
+		NewMetricStatistic(iter, scorer, gradientNorm, updateNorm, n.scorers(source)),
+		n.Inspect,
+		func() []ActivationStatistic {
+			statistics, _ := n.activationStatistics(source)
+			return statistics
+		},
+	)
+	return &trainingStatistic{
+		InspectionStatistic: statistic,
+		progress:            progress{learningRate: n.eta, trainedSamples: n.trainedSamples},
+		frozen:              func() IterationStatistic { return n.frozen().statistic(iter, source, gradientNorm, updateNorm) },
+	}
 }
 
-// Performs an epoch of online SGD.
-func (n *Network) completeEpoch(samples []Sample) {
-	for _, sample := range samples {
-		input := sample.Input
-		expected := sample.Output
-		actual := n.forwardPass(input)
-		diff := make([]float64, len(expected))
-		for i := 0; i < len(diff); i++ {
-			diff[i] = expected[i] - actual[i]
+// Creates a copy of the network holding copies of its parameters and gradients, which scores the same as the network
+// at the time of the copy while the network keeps training.
+func (n *Network) frozen() *Network {
+	weights, biases := n.copyParameters()
+	frozen := &Network{
+		neurons:        n.neurons,
+		activations:    n.activations,
+		layers:         constructLayers(n.neurons, n.activations),
+		loss:           n.loss,
+		eta:            n.eta,
+		trainedSamples: n.trainedSamples,
+		updateRatios:   append([]float64(nil), n.updateRatios...),
+		metrics:        append([]namedMetric(nil), n.metrics...),
+	}
+	for k, l := range frozen.layers {
+		for i, row := range l.getWeights() {
+			copy(row, weights[k][i])
+		}
+		copy(l.getBiases(), biases[k])
+	}
+	if n.weightGradients != nil {
+		frozen.weightGradients = make([][][]float64, len(n.weightGradients))
+		frozen.biasGradients = make([][]float64, len(n.biasGradients))
+		for k := range n.weightGradients {
+			frozen.weightGradients[k] = copyMatrix(n.weightGradients[k])
+			frozen.biasGradients[k] = append([]float64(nil), n.biasGradients[k]...)
 		}
+	}
+	return frozen
+}
 
-		for k := len(n.layers) - 1; k >= 0; k-- {
-			delta := n.layers[k].processError(diff)
-			var prevLayerOutput []float64
-			if k != 0 {
-				prevLayerOutput = n.layers[k-1].getOutputCache()
-			} else {
-				prevLayerOutput = input
-			}
+// Creates a Scorer for every metric added to the network, metrics without samples are computed on the given source.
+func (n *Network) scorers(source DataSource) map[string]Scorer {
+	scorers := make(map[string]Scorer, len(n.metrics))
+	for _, m := range n.metrics {
+		m := m
+		if m.samples == nil {
+			scorers[m.name] = func() float64 { return n.evaluate(LossFunction(m.metric), source) }
+		} else {
+			scorers[m.name] = func() float64 { return m.metric(n.predict, m.samples) }
+		}
+	}
+	return scorers
+}
+
+// Computes the loss of the network on the samples of the given source.
+// Samples of a streaming source are predicted batch-wise and only their predictions and expected outputs are kept, on
+// which the loss is computed at once, so that metrics which are not means over samples, such as F1Score or R2, are
+// exact.
+// Returns math.NaN if the source could not be read.
+func (n *Network) evaluate(loss LossFunction, source DataSource) float64 {
+	if slice, ok := source.(*sliceSource); ok {
+		return loss(n.predict, slice.samples)
+	}
+
+	iterator, err := source.Open()
+	if err != nil {
+		return math.NaN()
+	}
+	defer iterator.Close()
+
+	// the inputs of the kept samples are the predictions, which are mapped onto themselves when scoring
+	var predicted []Sample
+	for {
+		batch, err := nextBatch(iterator, sourceBatchSize)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return math.NaN()
+		}
+		for _, sample := range batch {
+			predicted = append(predicted, Sample{Input: n.predict(sample.Input), Output: sample.Output})
+		}
+	}
+	return loss(func(prediction []float64) []float64 { return prediction }, predicted)
+}
 
-			layerWeight := n.layers[k].getWeights()
-			layerBias := n.layers[k].getBiases()
+// Recovers the mean gradient of an epoch from the update made by online SGD with the given learning rate on the given
+// number of samples. An epoch without samples has a zero gradient.
+// Returns math.NaN if the learning rate is 0, as the parameters did not move and the gradient cannot be recovered.
+func gradientFromUpdate(update, eta float64, count int) float64 {
+	switch {
+	case count == 0:
+		return 0
+	case eta == 0:
+		return math.NaN()
+	default:
+		return update / (eta * float64(count))
+	}
+}
 
-			for i := 0; i < len(layerWeight); i++ {
-				for j := 0; j < len(layerWeight[i]); j++ {
-					layerWeight[i][j] += n.eta * delta[j] * prevLayerOutput[i]
-				}
+// Creates a deep copy of all the weights and biases of the network.
+func (n *Network) copyParameters() ([][][]float64, [][]float64) {
+	weights := make([][][]float64, len(n.layers))
+	biases := make([][]float64, len(n.layers))
+	for k, l := range n.layers {
+		weights[k] = copyMatrix(l.getWeights())
+		biases[k] = append([]float64(nil), l.getBiases()...)
+	}
+	return weights, biases
+}
+
+// Computes the mean gradients of the epoch from the parameters held before the epoch, which are overwritten with
+// the gradients, and stores them in the network.
+// As online SGD moves the parameters by -eta times the gradient of every sample, the mean gradient is equal to the
+// negated total update divided by eta and the number of samples. If eta is 0 the gradients cannot be recovered and
+// are math.NaN.
+// The ratio of the norm of the update of the weights to the norm of the weights is stored for every layer, it is
+// math.NaN if all weights of the layer are zero.
+// Returns the euclidean norm of the total update.
+func (n *Network) updateGradients(weights [][][]float64, biases [][]float64, count int) float64 {
+	scale := -gradientFromUpdate(1, n.eta, count)
+
+	sum := 0.
+	ratios := make([]float64, len(n.layers))
+	for k, l := range n.layers {
+		layerWeights := l.getWeights()
+		weightUpdate, weightNorm := 0., 0.
+		for i := range layerWeights {
+			for j := range layerWeights[i] {
+				update := layerWeights[i][j] - weights[k][i][j]
+				weightUpdate += update * update
+				weightNorm += layerWeights[i][j] * layerWeights[i][j]
+				weights[k][i][j] = update * scale
 			}
-			for i := 0; i < len(layerBias); i++ {
-				layerBias[i] += n.eta * delta[i]
+		}
+		sum += weightUpdate
+		ratios[k] = math.NaN()
+		if weightNorm > 0 {
+			ratios[k] = math.Sqrt(weightUpdate) / math.Sqrt(weightNorm)
+		}
+		for i, bias := range l.getBiases() {
+			update := bias - biases[k][i]
+			sum += update * update
+			biases[k][i] = update * scale
+		}
+	}
+
+	n.weightGradients, n.biasGradients, n.updateRatios = weights, biases, ratios
+	return math.Sqrt(sum)
+}
+
+// Opens an iterator over the samples of the given source in a random order.
+// In-memory sources are shuffled as a whole, other sources are shuffled through a bounded shuffle buffer.
+func (n *Network) shuffle(source DataSource) (SampleIterator, error) {
+	if slice, ok := source.(*sliceSource); ok {
+		return slice.shuffled(n.rng), nil
+	}
+
+	iterator, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	return NewShuffleIterator(iterator, n.shuffleBuffer, n.rng), nil
+}
+
+// Performs an epoch of online SGD, pulling shuffled batches of samples from the given source.
+// The context is checked before every sample, an interrupted epoch returns ctx.Err().
+// Returns the number of samples the network was trained on.
+func (n *Network) completeEpoch(ctx context.Context, source DataSource) (int, error) {
+	iterator, err := n.shuffle(source)
+	if err != nil {
+		return 0, err
+	}
+	defer iterator.Close()
+
+	count := 0
+	for {
+		batch, err := nextBatch(iterator, sourceBatchSize)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+
+		for _, sample := range batch {
+			if err := ctx.Err(); err != nil {
+				return count, err
 			}
+			n.update(sample)
+			n.trainedSamples++
+			count++
+		}
+	}
+}
+
+// Performs a single step of online SGD on the given sample.
+// The errors of all layers are computed from the parameters held before the step, so every layer moves along the
+// gradient of the sample at the same point.
+func (n *Network) update(sample Sample) {
+	deltas := n.backward(sample)
+	for k := range n.layers {
+		var prevLayerOutput []float64
+		if k != 0 {
+			prevLayerOutput = n.layers[k-1].getOutputCache()
+		} else {
+			prevLayerOutput = sample.Input
+		}
+
+		layerWeight := n.layers[k].getWeights()
+		layerBias := n.layers[k].getBiases()
+		delta := deltas[k]
 
-			diff = delta
+		for i := 0; i < len(layerWeight); i++ {
+			for j := 0; j < len(layerWeight[i]); j++ {
+				layerWeight[i][j] += n.eta * delta[j] * prevLayerOutput[i]
+			}
+		}
+		for i := 0; i < len(layerBias); i++ {
+			layerBias[i] += n.eta * delta[i]
 		}
 	}
 }
 
+// Performs a forward and a backward pass of the given sample and returns the error of every layer.
+// The errors are the negated gradients of half the squared error of the sample with respect to the net inputs of the
+// layers, all of them are computed before any parameter is updated.
+func (n *Network) backward(sample Sample) [][]float64 {
+	expected := sample.Output
+	actual := n.forwardPass(sample.Input)
+	diff := make([]float64, len(expected))
+	for i := 0; i < len(diff); i++ {
+		diff[i] = expected[i] - actual[i]
+	}
+
+	deltas := make([][]float64, len(n.layers))
+	for k := len(n.layers) - 1; k >= 0; k-- {
+		deltas[k] = n.layers[k].processError(diff)
+		diff = deltas[k]
+	}
+	return deltas
+}
+
 // Performs a model prediction.
 func (n *Network) Predict(input []float64) ([]float64, error) {
 	if len(input) != n.neurons[0] {
@@ -173,14 +541,20 @@ func (n *Network) Predict(input []float64) ([]float64, error) {
 		return nil, errors.New("this instance of Network has not been fitted yet")
 	}
 
-	output := n.layers[0].processInput(input)
+	return n.predict(input), nil
+}
+
+// Computes the output of the network without caching the outputs of the layers, which makes it safe for concurrent
+// use as long as the network is not being trained at the same time.
+func (n *Network) predict(input []float64) []float64 {
+	output := n.layers[0].computeOutput(input)
 	for i := 1; i < len(n.layers); i++ {
-		output = n.layers[i].processInput(output)
+		output = n.layers[i].computeOutput(output)
 	}
-	return output, nil
+	return output
 }
 
-// Performs a forward pass through the network.
+// Performs a forward pass through the network, caching the outputs of the layers for backpropagation.
 func (n *Network) forwardPass(input []float64) []float64 {
 	output := n.layers[0].processInput(input)
 	for i := 1; i < len(n.layers); i++ {
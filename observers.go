@@ -3,33 +3,129 @@ package feedforward
 import (
 	"fmt"
 	"math"
+	"sort"
+	"sync"
 )
 
 // Represents a type which holds information about the current Iteration of an iterative algorithm.
 // GetIteration returns the current Iteration number.
 // GetScore returns a loss function score (lower is better).
+// GetGradientNorm returns the L2 norm of the mean gradient over the previous Iteration, NaN if not available.
+// GetUpdateNorm returns the L2 norm of the parameter update made in the previous Iteration, NaN if not available.
 type IterationStatistic interface {
 	GetIteration() int
 	GetScore() float64
+	GetGradientNorm() float64
+	GetUpdateNorm() float64
+}
+
+// Represents an IterationStatistic which additionally holds any number of named metrics.
+// GetMetric returns the value of the metric with the given name and whether such a metric exists.
+// GetMetrics returns the values of all metrics keyed by name.
+// MetricNames returns the names of all metrics in lexicographic order.
+type MetricStatistic interface {
+	IterationStatistic
+	GetMetric(name string) (float64, bool)
+	GetMetrics() map[string]float64
+	MetricNames() []string
+}
+
+// Represents an IterationStatistic which additionally holds the progress of training at the time of the Iteration.
+// GetLearningRate returns the learning rate used by the Iteration.
+// GetTrainedSamples returns the total number of samples the model was trained on before the Iteration.
+type ProgressStatistic interface {
+	IterationStatistic
+	GetLearningRate() float64
+	GetTrainedSamples() int
+}
+
+// Type holding the progress of training, implementing the methods ProgressStatistic adds to IterationStatistic.
+type progress struct {
+	learningRate   float64
+	trainedSamples int
+}
+
+// Gets the learning rate of this progress.
+func (p progress) GetLearningRate() float64 {
+	return p.learningRate
+}
+
+// Gets the number of trained samples of this progress.
+func (p progress) GetTrainedSamples() int {
+	return p.trainedSamples
+}
+
+// Type extending a MetricStatistic with the progress of training.
+type metricProgress struct {
+	MetricStatistic
+	progress
+}
+
+// Type extending an InspectionStatistic with the progress of training.
+type inspectionProgress struct {
+	InspectionStatistic
+	progress
 }
 
 // Type representing a function which returns a loss function score
 type Scorer func() float64
 
-// Implementation of IterationStatistic which holds the current iteration number
-// and a Scorer type which computes the loss function score on demand.
+// Type holding a value which is computed by a Scorer at most once, on first access.
+type lazyValue struct {
+	once   sync.Once
+	scorer Scorer
+	value  float64
+}
+
+// Gets the value, computing it on first call.
+func (l *lazyValue) get() float64 {
+	l.once.Do(func() { l.value = l.scorer() })
+	return l.value
+}
+
+// Implementation of MetricStatistic which holds the current iteration number
+// and Scorer types which compute the loss function score and the metrics on demand.
 // The reason for using a Scorer instead of storing the score directly is to enable lazy evaluation as scores can be
 // expensive to compute and not all usages of IterationStatistic call the GetScore method.
-// On the first call of GetScore, the computed value will be cached in scoreCache variable.
+// Every value is computed at most once, on first access, and can be accessed concurrently.
 type iterationStatistic struct {
-	iteration  int
-	scorer     Scorer
-	scoreCache float64
+	iteration    int
+	score        *lazyValue
+	gradientNorm float64
+	updateNorm   float64
+	metrics      map[string]*lazyValue
+	names        []string
 }
 
-// Constructor for a new iterationStatistic, scoreCache is initially set to math.Nan.
+// Constructor for a new iterationStatistic.
+// Gradient and update norms are not available and are set to math.NaN.
 func NewIterationStatistic(iteration int, scorer Scorer) IterationStatistic {
-	return &iterationStatistic{iteration: iteration, scorer: scorer, scoreCache: math.NaN()}
+	return NewIterationStatisticWithNorms(iteration, scorer, math.NaN(), math.NaN())
+}
+
+// Constructor for a new iterationStatistic which additionally holds the gradient and update norms of the previous
+// Iteration.
+func NewIterationStatisticWithNorms(iteration int, scorer Scorer, gradientNorm, updateNorm float64) IterationStatistic {
+	return NewMetricStatistic(iteration, scorer, gradientNorm, updateNorm, nil)
+}
+
+// Constructor for a new iterationStatistic which additionally holds the gradient and update norms of the previous
+// Iteration and named metrics computed by the given scorers.
+func NewMetricStatistic(iteration int, scorer Scorer, gradientNorm, updateNorm float64, metrics map[string]Scorer) MetricStatistic {
+	statistic := &iterationStatistic{
+		iteration:    iteration,
+		score:        &lazyValue{scorer: scorer},
+		gradientNorm: gradientNorm,
+		updateNorm:   updateNorm,
+		metrics:      make(map[string]*lazyValue, len(metrics)),
+		names:        make([]string, 0, len(metrics)),
+	}
+	for name, metric := range metrics {
+		statistic.metrics[name] = &lazyValue{scorer: metric}
+		statistic.names = append(statistic.names, name)
+	}
+	sort.Strings(statistic.names)
+	return statistic
 }
 
 // Gets the iteration number of this iterationStatistic.
@@ -38,12 +134,53 @@ func (i *iterationStatistic) GetIteration() int {
 }
 
 // Gets the score of this iterationStatistic.
-// On first method call, the method calls the Scorer type and stores the computed value in scoreCache.
+// On first method call, the method calls the Scorer type and caches the computed value.
 func (i *iterationStatistic) GetScore() float64 {
-	if !(i.scoreCache == math.NaN()) {
-		i.scoreCache = i.scorer()
+	return i.score.get()
+}
+
+// Gets the gradient norm of this iterationStatistic.
+func (i *iterationStatistic) GetGradientNorm() float64 {
+	return i.gradientNorm
+}
+
+// Gets the update norm of this iterationStatistic.
+func (i *iterationStatistic) GetUpdateNorm() float64 {
+	return i.updateNorm
+}
+
+// Gets the metric with the given name, computing it on first call.
+func (i *iterationStatistic) GetMetric(name string) (float64, bool) {
+	metric, ok := i.metrics[name]
+	if !ok {
+		return math.NaN(), false
 	}
-	return i.scoreCache
+	return metric.get(), true
+}
+
+// Gets all metrics of this iterationStatistic.
+// Metrics which have not been computed yet are computed concurrently.
+func (i *iterationStatistic) GetMetrics() map[string]float64 {
+	var wg sync.WaitGroup
+	wg.Add(len(i.metrics))
+	for _, metric := range i.metrics {
+		go func(metric *lazyValue) {
+			defer wg.Done()
+			metric.get()
+		}(metric)
+	}
+	wg.Wait()
+
+	values := make(map[string]float64, len(i.metrics))
+	for name, metric := range i.metrics {
+		values[name] = metric.get()
+	}
+	return values
+}
+
+// Gets the names of all metrics of this iterationStatistic.
+func (i *iterationStatistic) MetricNames() []string {
+	return i.names
 }
 
 // Interface defining an observer of an iterative process.
@@ -61,30 +198,47 @@ type ModelSubject interface {
 
 // Struct implementing the entire ModelSubject interface.
 // Extended by types which publish Iteration statistics.
+// Observers can be added and removed concurrently with notifications. The slice of observers is never modified in
+// place, so a notification in progress is delivered to the observers registered when it started, and observers can
+// add or remove observers from within Update.
 type BaseSubject struct {
+	mutex     sync.RWMutex
 	observers []ModelObserver
 }
 
 // Adds an observer into a slice of observers.
 func (s *BaseSubject) AddObserver(observer ModelObserver) {
-	s.observers = append(s.observers, observer)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	observers := make([]ModelObserver, len(s.observers), len(s.observers)+1)
+	copy(observers, s.observers)
+	s.observers = append(observers, observer)
 }
 
-// Removes an observer from the slice of observers.
+// Removes the first occurrence of an observer from the slice of observers, preserving the order of the remaining
+// observers. Removing an observer which is not present does nothing.
 func (s *BaseSubject) RemoveObserver(observer ModelObserver) {
-	index := -1
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	for i, attached := range s.observers {
 		if attached == observer {
-			index = i
+			observers := make([]ModelObserver, 0, len(s.observers)-1)
+			observers = append(observers, s.observers[:i]...)
+			s.observers = append(observers, s.observers[i+1:]...)
+			return
 		}
 	}
-	s.observers[index], s.observers[len(s.observers)-1] = s.observers[len(s.observers)-1], s.observers[index]
-	s.observers = s.observers[0:len(s.observers)]
 }
 
 // Notifies all observers by iterating over the slice and calling Update method of every observer.
 func (s *BaseSubject) NotifyObservers(statistic IterationStatistic) {
-	for _, observer := range s.observers {
+	s.mutex.RLock()
+	observers := s.observers
+	s.mutex.RUnlock()
+
+	for _, observer := range observers {
 		observer.Update(statistic)
 	}
 }
@@ -121,3 +275,146 @@ func NewStOutLogger() ModelObserver {
 func (s *stoutLogger) Update(statistic IterationStatistic) {
 	fmt.Println(statistic.GetIteration(), statistic.GetScore())
 }
+
+// Represents a statistic which can be frozen cheaply, the values of the frozen statistic being computed on demand from
+// the state of the model at the time it was frozen.
+type freezable interface {
+	freeze() IterationStatistic
+}
+
+// Creates a snapshot of the given statistic, which can be accessed while the model keeps training.
+// Statistics published by a Network are frozen by copying the parameters of the network, so their values are only
+// computed when accessed. Other statistics are snapshotted by computing their score, metrics, and progress, layer
+// snapshots and activation statistics if available.
+func snapshot(statistic IterationStatistic) IterationStatistic {
+	if frozen, ok := statistic.(freezable); ok {
+		return frozen.freeze()
+	}
+
+	var values *progress
+	if p, ok := statistic.(ProgressStatistic); ok {
+		values = &progress{learningRate: p.GetLearningRate(), trainedSamples: p.GetTrainedSamples()}
+	}
+
+	metrics := make(map[string]Scorer)
+	if metricStatistic, ok := statistic.(MetricStatistic); ok {
+		for name, value := range metricStatistic.GetMetrics() {
+			value := value
+			metrics[name] = func() float64 { return value }
+		}
+	}
+	score := statistic.GetScore()
+	snapshot := NewMetricStatistic(statistic.GetIteration(), func() float64 { return score }, statistic.GetGradientNorm(), statistic.GetUpdateNorm(), metrics)
+
+	inspection, ok := statistic.(InspectionStatistic)
+	if !ok {
+		if values != nil {
+			return &metricProgress{MetricStatistic: snapshot, progress: *values}
+		}
+		return snapshot
+	}
+	layers, activations := inspection.GetLayers(), inspection.GetActivations()
+	inspected := NewInspectionStatistic(snapshot, func() []LayerSnapshot { return layers }, func() []ActivationStatistic { return activations })
+	if values != nil {
+		return &inspectionProgress{InspectionStatistic: inspected, progress: *values}
+	}
+	return inspected
+}
+
+// Observer type which delivers statistics to an underlying observer on a separate goroutine through a buffered
+// channel, so that slow observers, such as observers writing to disk or over the network, do not stall training.
+// As the model keeps training while statistics wait in the channel, every statistic is turned into a snapshot before
+// it is queued, which is safe to read on the delivering goroutine. Statistics of a Network only copy its parameters on
+// the training goroutine, their score, metrics and layer statistics are computed from the copy when the underlying
+// observer accesses them, which requires the DataSource being trained on to support concurrent passes. Statistics
+// which are dropped are not snapshotted at all. The observers of this package read the model only through the
+// statistic, so all of them can be wrapped.
+type AsyncObserver struct {
+	observer ModelObserver
+	updates  chan IterationStatistic
+	drop     bool
+	dropped  int64
+	closed   bool
+	mutex    sync.Mutex
+	senders  sync.WaitGroup
+	done     chan struct{}
+}
+
+// Constructor for generating a new AsyncObserver with a buffer of the given size, which is at least 1.
+// If drop is true, statistics are dropped when the buffer is full, otherwise Update blocks until there is space.
+func NewAsyncObserver(observer ModelObserver, buffer int, drop bool) *AsyncObserver {
+	if buffer < 1 {
+		buffer = 1
+	}
+	a := &AsyncObserver{
+		observer: observer,
+		updates:  make(chan IterationStatistic, buffer),
+		drop:     drop,
+		done:     make(chan struct{}),
+	}
+	go a.deliver()
+	return a
+}
+
+// Delivers queued statistics to the underlying observer until the observer is closed.
+func (a *AsyncObserver) deliver() {
+	defer close(a.done)
+	for statistic := range a.updates {
+		a.observer.Update(statistic)
+	}
+}
+
+// Queues a snapshot of the given statistic for delivery, statistics received after Close are ignored.
+// The mutex is not held while snapshotting or waiting for space in the buffer, so Dropped and Close are never blocked
+// by a slow observer.
+func (a *AsyncObserver) Update(statistic IterationStatistic) {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return
+	}
+	if a.drop && len(a.updates) == cap(a.updates) {
+		a.dropped++
+		a.mutex.Unlock()
+		return
+	}
+	// Close waits for registered senders before closing the channel, so the send below cannot panic
+	a.senders.Add(1)
+	a.mutex.Unlock()
+	defer a.senders.Done()
+
+	frozen := snapshot(statistic)
+	if !a.drop {
+		a.updates <- frozen
+		return
+	}
+	// the buffer may have been filled by a concurrent Update while snapshotting
+	select {
+	case a.updates <- frozen:
+	default:
+		a.mutex.Lock()
+		a.dropped++
+		a.mutex.Unlock()
+	}
+}
+
+// Gets the number of statistics dropped because the buffer was full.
+func (a *AsyncObserver) Dropped() int64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.dropped
+}
+
+// Stops accepting statistics and waits until all queued statistics are delivered.
+func (a *AsyncObserver) Close() {
+	a.mutex.Lock()
+	closing := !a.closed
+	a.closed = true
+	a.mutex.Unlock()
+
+	if closing {
+		a.senders.Wait()
+		close(a.updates)
+	}
+	<-a.done
+}
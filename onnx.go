@@ -0,0 +1,691 @@
+package feedforward
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Versions of the ONNX format and of the default operator set written by ExportONNX.
+const (
+	onnxIRVersion    = 7
+	onnxOpsetVersion = 13
+)
+
+// ONNX tensor element types.
+const (
+	onnxFloat  = 1
+	onnxDouble = 11
+)
+
+// ONNX attribute type of integer attributes.
+const onnxAttributeInt = 2
+
+// Key of the model metadata property holding the learning rate of an exported network.
+const onnxEtaKey = "feedforward.eta"
+
+// ONNX operators of the built-in activation functions.
+var onnxOperators = map[string]string{
+	"sigmoid": "Sigmoid",
+	"tanh":    "Tanh",
+	"relu":    "Relu",
+	"linear":  "Identity",
+}
+
+// Type holding the options of ExportONNX.
+// MatMul exports dense layers as a MatMul followed by an Add instead of a single Gemm.
+// Float32 stores the parameters and declares the input and output as single precision tensors instead of double
+// precision ones, which more runtimes support at the cost of precision.
+type ONNXOptions struct {
+	MatMul  bool
+	Float32 bool
+}
+
+// Writes the given fitted network as an ONNX model.
+// The model has a single input named "input" of shape [N, inputs] and a single output named "output" of shape
+// [N, outputs], N being the batch size. Every layer is exported as a Gemm, or a MatMul followed by an Add, followed by
+// the operator of its activation function, so only networks using the built-in activation functions can be exported.
+// Gemm weights are stored transposed as [outputs, inputs] with transB set, MatMul weights as [inputs, outputs].
+func ExportONNX(w io.Writer, network *Network, options ONNXOptions) error {
+	if !network.isFitted {
+		return errors.New("this instance of Network has not been fitted yet")
+	}
+
+	elemType := onnxDouble
+	if options.Float32 {
+		elemType = onnxFloat
+	}
+
+	graph := &protoWriter{}
+	graph.string(2, "feedforward")
+	current := "input"
+	last := len(network.layers) - 1
+	for k, l := range network.layers {
+		operator, ok := onnxOperators[network.activations[k].Name]
+		if !ok {
+			return fmt.Errorf("activation function %q of layer %d cannot be exported", network.activations[k].Name, k)
+		}
+
+		prefix := "layer" + strconv.Itoa(k)
+		weights, biases := l.getWeights(), l.getBiases()
+		in, out := len(weights), len(biases)
+		flat := make([]float64, 0, in*out)
+		if options.MatMul {
+			for i := 0; i < in; i++ {
+				flat = append(flat, weights[i]...)
+			}
+			graph.message(5, onnxTensor(prefix+".weight", []int{in, out}, flat, options.Float32))
+			graph.message(5, onnxTensor(prefix+".bias", []int{out}, biases, options.Float32))
+			graph.message(1, onnxNode("MatMul", prefix+".matmul", []string{current, prefix + ".weight"}, prefix+".product"))
+			graph.message(1, onnxNode("Add", prefix+".add", []string{prefix + ".product", prefix + ".bias"}, prefix+".net"))
+		} else {
+			for j := 0; j < out; j++ {
+				for i := 0; i < in; i++ {
+					flat = append(flat, weights[i][j])
+				}
+			}
+			graph.message(5, onnxTensor(prefix+".weight", []int{out, in}, flat, options.Float32))
+			graph.message(5, onnxTensor(prefix+".bias", []int{out}, biases, options.Float32))
+			graph.message(1, onnxNode("Gemm", prefix+".gemm", []string{current, prefix + ".weight", prefix + ".bias"}, prefix+".net",
+				onnxIntAttribute("transB", 1)))
+		}
+
+		output := prefix + ".output"
+		if k == last {
+			output = "output"
+		}
+		graph.message(1, onnxNode(operator, prefix+"."+network.activations[k].Name, []string{prefix + ".net"}, output))
+		current = output
+	}
+	graph.message(11, onnxValueInfo("input", elemType, network.neurons[0]))
+	graph.message(12, onnxValueInfo("output", elemType, network.neurons[last+1]))
+
+	opset := &protoWriter{}
+	opset.string(1, "")
+	opset.int(2, onnxOpsetVersion)
+
+	eta := &protoWriter{}
+	eta.string(1, onnxEtaKey)
+	eta.string(2, strconv.FormatFloat(network.eta, 'g', -1, 64))
+
+	model := &protoWriter{}
+	model.int(1, onnxIRVersion)
+	model.string(2, "feedforward")
+	model.message(7, graph)
+	model.message(8, opset)
+	model.message(14, eta)
+
+	_, err := w.Write(model.buffer)
+	return err
+}
+
+// Encodes a TensorProto holding the given values in row-major order.
+func onnxTensor(name string, dims []int, values []float64, single bool) *protoWriter {
+	tensor := &protoWriter{}
+	for _, dim := range dims {
+		tensor.int(1, int64(dim))
+	}
+	if single {
+		tensor.int(2, onnxFloat)
+		narrowed := make([]float32, len(values))
+		for i, value := range values {
+			narrowed[i] = float32(value)
+		}
+		tensor.packedFloats(4, narrowed)
+	} else {
+		tensor.int(2, onnxDouble)
+		tensor.packedDoubles(10, values)
+	}
+	tensor.string(8, name)
+	return tensor
+}
+
+// Encodes a NodeProto of the default domain with a single output.
+func onnxNode(operator, name string, inputs []string, output string, attributes ...*protoWriter) *protoWriter {
+	node := &protoWriter{}
+	for _, input := range inputs {
+		node.string(1, input)
+	}
+	node.string(2, output)
+	node.string(3, name)
+	node.string(4, operator)
+	for _, attribute := range attributes {
+		node.message(5, attribute)
+	}
+	return node
+}
+
+// Encodes an integer AttributeProto.
+func onnxIntAttribute(name string, value int64) *protoWriter {
+	attribute := &protoWriter{}
+	attribute.string(1, name)
+	attribute.int(3, value)
+	attribute.int(20, onnxAttributeInt)
+	return attribute
+}
+
+// Encodes a ValueInfoProto of a matrix tensor of shape [N, size], N being the batch size.
+func onnxValueInfo(name string, elemType int, size int) *protoWriter {
+	batch := &protoWriter{}
+	batch.string(2, "N")
+	features := &protoWriter{}
+	features.int(1, int64(size))
+	shape := &protoWriter{}
+	shape.message(1, batch)
+	shape.message(1, features)
+
+	tensorType := &protoWriter{}
+	tensorType.int(1, int64(elemType))
+	tensorType.message(2, shape)
+	typ := &protoWriter{}
+	typ.message(1, tensorType)
+
+	info := &protoWriter{}
+	info.string(1, name)
+	info.message(2, typ)
+	return info
+}
+
+// Type holding the parts of an ONNX graph needed for importing a network.
+type onnxGraph struct {
+	nodes        []onnxGraphNode
+	initializers map[string]onnxGraphTensor
+	inputs       []onnxGraphValue
+	outputs      []onnxGraphValue
+}
+
+// Type holding a decoded NodeProto along with its integer and float attributes.
+type onnxGraphNode struct {
+	name     string
+	operator string
+	inputs   []string
+	outputs  []string
+	ints     map[string]int64
+	floats   map[string]float64
+}
+
+// Type holding a decoded TensorProto.
+type onnxGraphTensor struct {
+	dims   []int64
+	values []float64
+}
+
+// Type holding the name and the size of the last dimension, if known, of a graph input or output.
+type onnxGraphValue struct {
+	name string
+	size int64
+}
+
+// Reads a network from an ONNX model, such as one written by ExportONNX.
+// Only models consisting of a chain of dense layers are supported: every layer must be a Gemm, or a MatMul optionally
+// followed by an Add, followed by a Sigmoid, Tanh, Relu or Identity, all parameters being float or double initializers.
+// Dense layers which are not followed by an activation function are linear.
+// The learning rate is restored for models written by ExportONNX and is 0 otherwise, so it has to be set using
+// SetLearningRate before training an imported network further.
+func ImportONNX(r io.Reader) (*Network, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var graph *onnxGraph
+	eta := 0.0
+	err = decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		if wireType != wireBytes || field != 7 && field != 14 {
+			return false, nil
+		}
+		value, err := reader.bytes()
+		if err != nil {
+			return true, err
+		}
+		if field == 7 {
+			graph, err = decodeONNXGraph(value)
+			return true, err
+		}
+		key, property, err := decodeONNXProperty(value)
+		if err == nil && key == onnxEtaKey {
+			eta, err = strconv.ParseFloat(property, 64)
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ONNX model: %w", err)
+	}
+	if graph == nil {
+		return nil, errors.New("ONNX model has no graph")
+	}
+	return graph.network(eta)
+}
+
+// Type holding a dense layer being imported, which is complete once its activation function is known.
+type onnxLayer struct {
+	weights [][]float64
+	biases  []float64
+}
+
+// Converts the graph into a network.
+func (g *onnxGraph) network(eta float64) (*Network, error) {
+	var inputs []onnxGraphValue
+	for _, input := range g.inputs {
+		if _, ok := g.initializers[input.name]; !ok {
+			inputs = append(inputs, input)
+		}
+	}
+	if len(inputs) != 1 || len(g.outputs) != 1 {
+		return nil, fmt.Errorf("ONNX graph must have a single input and a single output, found %d and %d", len(inputs), len(g.outputs))
+	}
+
+	current := inputs[0].name
+	neurons := []int{int(inputs[0].size)}
+	var activations []ActivationFunction
+	var weights [][][]float64
+	var biases [][]float64
+	var pending *onnxLayer
+	// completes the pending dense layer with the given activation function
+	complete := func(activation ActivationFunction) {
+		if pending.biases == nil {
+			pending.biases = make([]float64, len(pending.weights[0]))
+		}
+		neurons = append(neurons, len(pending.biases))
+		activations = append(activations, activation)
+		weights = append(weights, pending.weights)
+		biases = append(biases, pending.biases)
+		pending = nil
+	}
+	for i, node := range g.nodes {
+		if node.name == "" {
+			node.name = fmt.Sprintf("%s #%d", node.operator, i)
+		}
+		if len(node.outputs) != 1 {
+			return nil, fmt.Errorf("node %q of operator %s must have a single output", node.name, node.operator)
+		}
+
+		switch node.operator {
+		case "Gemm", "MatMul":
+			// a dense layer directly followed by another one has a linear activation
+			if pending != nil {
+				complete(Linear())
+			}
+			if len(node.inputs) < 2 || node.inputs[0] != current {
+				return nil, fmt.Errorf("node %q does not take the output of the previous layer as its first input", node.name)
+			}
+			layer, err := g.denseLayer(node)
+			if err != nil {
+				return nil, fmt.Errorf("node %q: %w", node.name, err)
+			}
+			if len(weights) == 0 && neurons[0] <= 0 {
+				neurons[0] = len(layer.weights)
+			}
+			if len(layer.weights) != neurons[len(neurons)-1] {
+				return nil, fmt.Errorf("node %q takes %d inputs, the previous layer has %d outputs", node.name, len(layer.weights), neurons[len(neurons)-1])
+			}
+			pending = layer
+		case "Add":
+			if pending == nil || pending.biases != nil || len(node.inputs) != 2 {
+				return nil, fmt.Errorf("node %q of operator Add does not follow a MatMul", node.name)
+			}
+			bias := node.inputs[1]
+			if node.inputs[1] == current {
+				bias = node.inputs[0]
+			} else if node.inputs[0] != current {
+				return nil, fmt.Errorf("node %q does not take the output of the previous node", node.name)
+			}
+			values, err := g.vector(bias, len(pending.weights[0]), 1)
+			if err != nil {
+				return nil, fmt.Errorf("node %q: %w", node.name, err)
+			}
+			pending.biases = values
+		default:
+			activation, err := onnxActivation(node.operator)
+			if err != nil {
+				return nil, fmt.Errorf("node %q: %w", node.name, err)
+			}
+			if pending == nil || len(node.inputs) != 1 || node.inputs[0] != current {
+				return nil, fmt.Errorf("node %q of operator %s does not follow a dense layer", node.name, node.operator)
+			}
+			complete(activation)
+		}
+		current = node.outputs[0]
+	}
+
+	if pending != nil {
+		complete(Linear())
+	}
+	if len(activations) == 0 {
+		return nil, errors.New("ONNX graph has no dense layers")
+	}
+	if current != g.outputs[0].name {
+		return nil, fmt.Errorf("output %q of the ONNX graph is not the output of the last layer", g.outputs[0].name)
+	}
+	return newFittedNetwork(neurons, activations, weights, biases, eta), nil
+}
+
+// Reads the weights, and the biases of a Gemm, of the dense layer computed by the given node.
+func (g *onnxGraph) denseLayer(node onnxGraphNode) (*onnxLayer, error) {
+	matrix, ok := g.initializers[node.inputs[1]]
+	if !ok {
+		return nil, fmt.Errorf("weights %q are not an initializer", node.inputs[1])
+	}
+	if len(matrix.dims) != 2 {
+		return nil, fmt.Errorf("weights %q are not a matrix", node.inputs[1])
+	}
+
+	alpha, beta, transposed := 1.0, 1.0, false
+	if node.operator == "Gemm" {
+		if node.ints["transA"] != 0 {
+			return nil, errors.New("Gemm with transA is not supported")
+		}
+		transposed = node.ints["transB"] != 0
+		if value, ok := node.floats["alpha"]; ok {
+			alpha = value
+		}
+		if value, ok := node.floats["beta"]; ok {
+			beta = value
+		}
+	} else if len(node.inputs) != 2 {
+		return nil, errors.New("MatMul must have two inputs")
+	}
+
+	rows, columns := int(matrix.dims[0]), int(matrix.dims[1])
+	if rows <= 0 || columns <= 0 {
+		return nil, fmt.Errorf("weights %q have invalid shape %v", node.inputs[1], matrix.dims)
+	}
+	in, out := rows, columns
+	if transposed {
+		in, out = columns, rows
+	}
+	layer := &onnxLayer{weights: make([][]float64, in)}
+	for i := range layer.weights {
+		layer.weights[i] = make([]float64, out)
+		for j := range layer.weights[i] {
+			if transposed {
+				layer.weights[i][j] = alpha * matrix.values[j*columns+i]
+			} else {
+				layer.weights[i][j] = alpha * matrix.values[i*columns+j]
+			}
+		}
+	}
+
+	if node.operator == "Gemm" {
+		layer.biases = make([]float64, out)
+		if len(node.inputs) > 2 && node.inputs[2] != "" {
+			values, err := g.vector(node.inputs[2], out, beta)
+			if err != nil {
+				return nil, err
+			}
+			layer.biases = values
+		}
+	}
+	return layer, nil
+}
+
+// Reads an initializer broadcast to a vector of the given size, scaled by the given factor.
+func (g *onnxGraph) vector(name string, size int, scale float64) ([]float64, error) {
+	tensor, ok := g.initializers[name]
+	if !ok {
+		return nil, fmt.Errorf("biases %q are not an initializer", name)
+	}
+	if len(tensor.values) != size && len(tensor.values) != 1 {
+		return nil, fmt.Errorf("biases %q hold %d values, expected %d", name, len(tensor.values), size)
+	}
+	vector := make([]float64, size)
+	for i := range vector {
+		vector[i] = scale * tensor.values[i%len(tensor.values)]
+	}
+	return vector, nil
+}
+
+// Returns the built-in activation function computed by the given ONNX operator.
+func onnxActivation(operator string) (ActivationFunction, error) {
+	for name, op := range onnxOperators {
+		if op == operator {
+			return ActivationByName(name)
+		}
+	}
+	return ActivationFunction{}, fmt.Errorf("unsupported ONNX operator %s", operator)
+}
+
+// Decodes a GraphProto.
+func decodeONNXGraph(data []byte) (*onnxGraph, error) {
+	graph := &onnxGraph{initializers: make(map[string]onnxGraphTensor)}
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		if wireType != wireBytes || field != 1 && field != 5 && field != 11 && field != 12 {
+			return false, nil
+		}
+		value, err := reader.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch field {
+		case 1:
+			var node onnxGraphNode
+			node, err = decodeONNXNode(value)
+			graph.nodes = append(graph.nodes, node)
+		case 5:
+			var name string
+			var tensor onnxGraphTensor
+			name, tensor, err = decodeONNXTensor(value)
+			graph.initializers[name] = tensor
+		case 11, 12:
+			var info onnxGraphValue
+			info, err = decodeONNXValueInfo(value)
+			if field == 11 {
+				graph.inputs = append(graph.inputs, info)
+			} else {
+				graph.outputs = append(graph.outputs, info)
+			}
+		}
+		return true, err
+	})
+	return graph, err
+}
+
+// Decodes a NodeProto.
+func decodeONNXNode(data []byte) (onnxGraphNode, error) {
+	node := onnxGraphNode{ints: make(map[string]int64), floats: make(map[string]float64)}
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		if wireType != wireBytes || field < 1 || field > 5 {
+			return false, nil
+		}
+		value, err := reader.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch field {
+		case 1:
+			node.inputs = append(node.inputs, string(value))
+		case 2:
+			node.outputs = append(node.outputs, string(value))
+		case 3:
+			node.name = string(value)
+		case 4:
+			node.operator = string(value)
+		case 5:
+			err = decodeONNXAttribute(value, &node)
+		}
+		return true, err
+	})
+	return node, err
+}
+
+// Decodes an AttributeProto into the attributes of the given node, ignoring attributes which are not scalars.
+func decodeONNXAttribute(data []byte, node *onnxGraphNode) error {
+	var name string
+	var integer *int64
+	var float *float64
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			value, err := reader.bytes()
+			name = string(value)
+			return true, err
+		case field == 2 && wireType == wireFixed32:
+			value, err := reader.fixed32()
+			f := float64(math.Float32frombits(value))
+			float = &f
+			return true, err
+		case field == 3 && wireType == wireVarint:
+			value, err := reader.varint()
+			i := int64(value)
+			integer = &i
+			return true, err
+		default:
+			return false, nil
+		}
+	})
+	if integer != nil {
+		node.ints[name] = *integer
+	}
+	if float != nil {
+		node.floats[name] = *float
+	}
+	return err
+}
+
+// Decodes a TensorProto holding float or double values.
+func decodeONNXTensor(data []byte) (string, onnxGraphTensor, error) {
+	var name string
+	var tensor onnxGraphTensor
+	var dataType int64
+	var floats []float32
+	var raw []byte
+	external := false
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			tensor.dims, err = reader.ints(wireType, tensor.dims)
+		case 2:
+			var value uint64
+			value, err = reader.varint()
+			dataType = int64(value)
+		case 4:
+			floats, err = reader.floats(wireType, floats)
+		case 8:
+			var value []byte
+			value, err = reader.bytes()
+			name = string(value)
+		case 9:
+			raw, err = reader.bytes()
+		case 10:
+			tensor.values, err = reader.doubles(wireType, tensor.values)
+		case 14:
+			var value uint64
+			value, err = reader.varint()
+			external = value != 0
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return "", tensor, err
+	}
+	if external {
+		return "", tensor, fmt.Errorf("initializer %q is stored externally, which is not supported", name)
+	}
+
+	switch dataType {
+	case onnxFloat:
+		if raw != nil {
+			if len(raw)%4 != 0 {
+				return "", tensor, fmt.Errorf("raw data of initializer %q has invalid length", name)
+			}
+			for i := 0; i < len(raw); i += 4 {
+				floats = append(floats, math.Float32frombits(binary.LittleEndian.Uint32(raw[i:])))
+			}
+		}
+		for _, value := range floats {
+			tensor.values = append(tensor.values, float64(value))
+		}
+	case onnxDouble:
+		if raw != nil {
+			if len(raw)%8 != 0 {
+				return "", tensor, fmt.Errorf("raw data of initializer %q has invalid length", name)
+			}
+			for i := 0; i < len(raw); i += 8 {
+				tensor.values = append(tensor.values, math.Float64frombits(binary.LittleEndian.Uint64(raw[i:])))
+			}
+		}
+	default:
+		return "", tensor, fmt.Errorf("initializer %q has unsupported data type %d", name, dataType)
+	}
+
+	size := int64(1)
+	for _, dim := range tensor.dims {
+		size *= dim
+	}
+	if size != int64(len(tensor.values)) {
+		return "", tensor, fmt.Errorf("initializer %q holds %d values, its shape %v requires %d", name, len(tensor.values), tensor.dims, size)
+	}
+	return name, tensor, nil
+}
+
+// Decodes the name of a ValueInfoProto and the last dimension of its tensor shape, which is 0 if unknown.
+func decodeONNXValueInfo(data []byte) (onnxGraphValue, error) {
+	var info onnxGraphValue
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		if wireType != wireBytes || field != 1 && field != 2 {
+			return false, nil
+		}
+		value, err := reader.bytes()
+		if err != nil {
+			return true, err
+		}
+		if field == 1 {
+			info.name = string(value)
+			return true, nil
+		}
+		// type.tensor_type.shape.dim, of which the last one is read
+		for _, nested := range []int{1, 2, 1} {
+			if value, err = decodeONNXField(value, nested); err != nil || value == nil {
+				return true, err
+			}
+		}
+		err = decodeMessage(value, func(reader *protoReader, field int, wireType int) (bool, error) {
+			if field != 1 || wireType != wireVarint {
+				return false, nil
+			}
+			size, err := reader.varint()
+			info.size = int64(size)
+			return true, err
+		})
+		return true, err
+	})
+	return info, err
+}
+
+// Decodes the last occurrence of the given embedded message field, nil if the field is not present.
+func decodeONNXField(data []byte, field int) ([]byte, error) {
+	var found []byte
+	err := decodeMessage(data, func(reader *protoReader, f int, wireType int) (bool, error) {
+		if f != field || wireType != wireBytes {
+			return false, nil
+		}
+		var err error
+		found, err = reader.bytes()
+		return true, err
+	})
+	return found, err
+}
+
+// Decodes a StringStringEntryProto.
+func decodeONNXProperty(data []byte) (string, string, error) {
+	var key, value string
+	err := decodeMessage(data, func(reader *protoReader, field int, wireType int) (bool, error) {
+		if wireType != wireBytes || field != 1 && field != 2 {
+			return false, nil
+		}
+		decoded, err := reader.bytes()
+		if field == 1 {
+			key = string(decoded)
+		} else {
+			value = string(decoded)
+		}
+		return true, err
+	})
+	return key, value, err
+}
@@ -0,0 +1,191 @@
+package feedforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Version of the format used for serializing models.
+const FormatVersion = 1
+
+// Type holding the serialized form of a Network.
+type networkJSON struct {
+	Version     int         `json:"version"`
+	Neurons     []int       `json:"neurons"`
+	Activations []string    `json:"activations"`
+	Eta         float64     `json:"eta"`
+	Layers      []layerJSON `json:"layers"`
+}
+
+// Type holding the serialized parameters of a single layer.
+type layerJSON struct {
+	Weights [][]float64 `json:"weights"`
+	Biases  []float64   `json:"biases"`
+}
+
+// Encodes the topology, activation functions, learning rate and learned parameters of the network into JSON.
+// The initializer, stopping condition, loss and observers are not serialized.
+func (n *Network) MarshalJSON() ([]byte, error) {
+	if !n.isFitted {
+		return nil, errors.New("this instance of Network has not been fitted yet")
+	}
+
+	encoded := networkJSON{
+		Version:     FormatVersion,
+		Neurons:     n.neurons,
+		Activations: make([]string, len(n.activations)),
+		Eta:         n.eta,
+		Layers:      make([]layerJSON, len(n.layers)),
+	}
+	for i, activation := range n.activations {
+		if activation.Name == "" {
+			return nil, fmt.Errorf("activation function of layer %d has no name and cannot be serialized", i)
+		}
+		encoded.Activations[i] = activation.Name
+	}
+	for k, l := range n.layers {
+		encoded.Layers[k] = layerJSON{Weights: l.getWeights(), Biases: l.getBiases()}
+	}
+	return json.Marshal(encoded)
+}
+
+// Decodes a network encoded by MarshalJSON, replacing the topology and parameters of this network.
+// The decoded network is fitted and can be used for prediction right away.
+// As the stopping condition is not serialized, a decoded network which was not constructed by NewNetwork stops
+// training immediately until a stopping condition is set using SetStoppingCondition.
+func (n *Network) UnmarshalJSON(data []byte) error {
+	var decoded networkJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version != FormatVersion {
+		return fmt.Errorf("unsupported model format version %d", decoded.Version)
+	}
+	if len(decoded.Neurons) < 2 {
+		return errors.New("network must have at least an input and an output layer")
+	}
+	for k, count := range decoded.Neurons {
+		if count < 1 {
+			return fmt.Errorf("layer %d has %d neurons, every layer must have at least one", k, count)
+		}
+	}
+	if len(decoded.Activations) != len(decoded.Neurons)-1 || len(decoded.Layers) != len(decoded.Neurons)-1 {
+		return errors.New("number of activation functions and layers does not match the topology")
+	}
+
+	activations := make([]ActivationFunction, len(decoded.Activations))
+	for i, name := range decoded.Activations {
+		activation, err := ActivationByName(name)
+		if err != nil {
+			return err
+		}
+		activations[i] = activation
+	}
+
+	layers := constructLayers(decoded.Neurons, activations)
+	for k, l := range layers {
+		weights, biases := l.getWeights(), l.getBiases()
+		if len(decoded.Layers[k].Weights) != len(weights) || len(decoded.Layers[k].Biases) != len(biases) {
+			return fmt.Errorf("parameters of layer %d do not match the topology", k)
+		}
+		for i := range weights {
+			if len(decoded.Layers[k].Weights[i]) != len(weights[i]) {
+				return fmt.Errorf("parameters of layer %d do not match the topology", k)
+			}
+			copy(weights[i], decoded.Layers[k].Weights[i])
+		}
+		copy(biases, decoded.Layers[k].Biases)
+	}
+
+	n.neurons = decoded.Neurons
+	n.activations = activations
+	n.layers = layers
+	n.eta = decoded.Eta
+	n.isFitted = true
+	if n.initializer == nil {
+		n.initializer = NewUniformInitializer(-1, 1)
+	}
+	if n.stop.IsMet == nil {
+		n.stop = NewMaxIter(0)
+	}
+	if n.loss == nil {
+		n.loss = MeanSquareError
+	}
+	if n.rng == nil {
+		n.shuffleBuffer = defaultShuffleBuffer
+		n.rng = newRand()
+	}
+	return nil
+}
+
+// Constructs a fitted network holding the given parameters, which must match the topology.
+// Like a decoded network, the constructed network stops training immediately until a stopping condition is set.
+func newFittedNetwork(neurons []int, activations []ActivationFunction, weights [][][]float64, biases [][]float64, eta float64) *Network {
+	network := NewNetwork(neurons, activations, NewUniformInitializer(-1, 1), NewMaxIter(0), eta)
+	for k, l := range network.layers {
+		for i, row := range l.getWeights() {
+			copy(row, weights[k][i])
+		}
+		copy(l.getBiases(), biases[k])
+	}
+	network.isFitted = true
+	return network
+}
+
+// Saves the given model, such as a Network or a Pipeline, to a JSON file at the given path.
+func SaveModel(path string, model json.Marshaler) error {
+	data, err := model.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Loads a Network saved by SaveModel.
+func LoadNetwork(path string) (*Network, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	network := &Network{}
+	if err := network.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// Loads a model saved by SaveModel, which is either a Network or a Pipeline.
+// The concrete type can be recovered using a type switch on *Network and *Pipeline.
+func LoadModel(path string) (Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalModel(data)
+}
+
+// Decodes a Network or a Pipeline encoded by MarshalJSON, detecting the type from the encoded fields.
+func UnmarshalModel(data []byte) (Model, error) {
+	var header struct {
+		Network json.RawMessage `json:"network"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Network != nil {
+		pipeline := &Pipeline{}
+		if err := pipeline.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		return pipeline, nil
+	}
+
+	network := &Network{}
+	if err := network.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
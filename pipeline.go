@@ -0,0 +1,269 @@
+package feedforward
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Represents a chain of transformer steps followed by a Network.
+// Fitting a pipeline fits every step in order on the output of the previous step and then fits the network on the
+// transformed samples. Predictions take raw inputs, which are transformed by every step before being passed to the
+// network, and the outputs of the network are inverse transformed by every step in reverse order.
+// A pipeline is serialized as a single artifact, so preprocessing is always applied at prediction time.
+// The encoders of categorical columns and of class labels used to prepare the samples can be stored in the pipeline
+// as well, so that raw data is encoded and outputs are decoded the same way at prediction time.
+type Pipeline struct {
+	steps    []Transformer
+	network  *Network
+	encoders map[string]CategoricalEncoder
+	labels   *LabelEncoder
+}
+
+// Constructor of a pipeline consisting of the given steps and a final network.
+func NewPipeline(network *Network, steps ...Transformer) *Pipeline {
+	return &Pipeline{steps: steps, network: network}
+}
+
+// Gets the final network of the pipeline.
+func (p *Pipeline) Network() *Network {
+	return p.network
+}
+
+// Gets the transformer steps of the pipeline.
+func (p *Pipeline) Steps() []Transformer {
+	return p.steps
+}
+
+// Sets the fitted encoders of the categorical input columns, keyed by column name, and the encoder of the class labels,
+// either of which can be nil. The encoders are applied to CSV data read using the options returned by CSVOptions, and
+// the encoder of the class labels decodes the outputs of PredictLabel.
+func (p *Pipeline) SetEncoders(columns map[string]CategoricalEncoder, labels *LabelEncoder) {
+	p.encoders = columns
+	p.labels = labels
+}
+
+// Gets the encoders of the categorical input columns, keyed by column name.
+func (p *Pipeline) Encoders() map[string]CategoricalEncoder {
+	return p.encoders
+}
+
+// Gets the encoder of the class labels, nil if none was set.
+func (p *Pipeline) Labels() *LabelEncoder {
+	return p.labels
+}
+
+// Returns a copy of the given options with the encoders of the pipeline assigned to their columns, so that CSV data
+// read using the returned options is encoded the same way as the data the pipeline was trained on.
+// The encoder of the class labels is assigned to the output column if the options select a single one.
+func (p *Pipeline) CSVOptions(options CSVOptions) CSVOptions {
+	encoders := make(map[string]CategoricalEncoder, len(options.Encoders)+len(p.encoders)+1)
+	for column, encoder := range options.Encoders {
+		encoders[column] = encoder
+	}
+	for column, encoder := range p.encoders {
+		encoders[column] = encoder
+	}
+	indices := make(map[int]CategoricalEncoder, len(options.EncoderIndices)+1)
+	for index, encoder := range options.EncoderIndices {
+		indices[index] = encoder
+	}
+
+	if p.labels != nil && len(options.OutputColumns)+len(options.OutputIndices) == 1 {
+		if len(options.OutputColumns) == 1 {
+			encoders[options.OutputColumns[0]] = p.labels
+		} else {
+			indices[options.OutputIndices[0]] = p.labels
+		}
+	}
+	options.Encoders, options.EncoderIndices = encoders, indices
+	return options
+}
+
+// Fits every step of the pipeline and the network to the given samples.
+// Scores published by the network during training are computed on transformed samples.
+func (p *Pipeline) Fit(samples []Sample) {
+	_ = p.FitContext(context.Background(), samples)
+}
+
+// Fits every step of the pipeline and the network to the given samples, stopping early if the given context is done.
+// Returns ctx.Err() if training was interrupted or an error if the samples could not be fitted or transformed, nil
+// otherwise.
+func (p *Pipeline) FitContext(ctx context.Context, samples []Sample) error {
+	for _, step := range p.steps {
+		if err := step.Fit(samples); err != nil {
+			return err
+		}
+		transformed, err := TransformSamples(step, samples)
+		if err != nil {
+			return err
+		}
+		samples = transformed
+	}
+	return p.network.FitContext(ctx, samples)
+}
+
+// Fits the network to the given samples transformed by already fitted steps.
+// Steps are fitted only if the pipeline has not been fitted yet.
+func (p *Pipeline) PartialFit(samples []Sample) {
+	_ = p.PartialFitContext(context.Background(), samples)
+}
+
+// Behaves like PartialFit, stopping early if the given context is done.
+// Returns ctx.Err() if training was interrupted or an error if the samples could not be transformed, nil otherwise.
+func (p *Pipeline) PartialFitContext(ctx context.Context, samples []Sample) error {
+	if !p.network.isFitted {
+		return p.FitContext(ctx, samples)
+	}
+	transformed, err := p.transform(samples)
+	if err != nil {
+		return err
+	}
+	return p.network.PartialFitContext(ctx, transformed)
+}
+
+// Transforms the given samples by every step of the pipeline.
+func (p *Pipeline) transform(samples []Sample) ([]Sample, error) {
+	for _, step := range p.steps {
+		transformed, err := TransformSamples(step, samples)
+		if err != nil {
+			return nil, err
+		}
+		samples = transformed
+	}
+	return samples, nil
+}
+
+// Performs a prediction on a raw input.
+func (p *Pipeline) Predict(input []float64) ([]float64, error) {
+	var err error
+	for _, step := range p.steps {
+		if input, err = step.TransformInput(input); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := p.network.Predict(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		if output, err = p.steps[i].InverseTransformOutput(output); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// Performs a prediction on a raw input and decodes the output into the class label with the highest score.
+// Returns an error if the pipeline has no encoder of the class labels.
+func (p *Pipeline) PredictLabel(input []float64) (string, error) {
+	if p.labels == nil {
+		return "", errors.New("pipeline has no encoder of the class labels")
+	}
+	output, err := p.Predict(input)
+	if err != nil {
+		return "", err
+	}
+	return p.labels.Decode(output)
+}
+
+// Type holding the serialized form of a Pipeline.
+type pipelineJSON struct {
+	Version  int                        `json:"version"`
+	Steps    []json.RawMessage          `json:"steps"`
+	Encoders map[string]json.RawMessage `json:"encoders,omitempty"`
+	Labels   *LabelEncoder              `json:"labels,omitempty"`
+	Network  json.RawMessage            `json:"network"`
+}
+
+// Encodes the steps, the encoders and the network of the pipeline into JSON.
+func (p *Pipeline) MarshalJSON() ([]byte, error) {
+	encoded := pipelineJSON{Version: FormatVersion, Steps: make([]json.RawMessage, len(p.steps)), Labels: p.labels}
+	for i, step := range p.steps {
+		data, err := json.Marshal(step)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Steps[i] = data
+	}
+	if len(p.encoders) > 0 {
+		encoded.Encoders = make(map[string]json.RawMessage, len(p.encoders))
+		for column, encoder := range p.encoders {
+			data, err := json.Marshal(encoder)
+			if err != nil {
+				return nil, err
+			}
+			encoded.Encoders[column] = data
+		}
+	}
+
+	network, err := p.network.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	encoded.Network = network
+	return json.Marshal(encoded)
+}
+
+// Decodes a pipeline encoded by MarshalJSON, replacing the steps and the network of this pipeline.
+func (p *Pipeline) UnmarshalJSON(data []byte) error {
+	var decoded pipelineJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version != FormatVersion {
+		return fmt.Errorf("unsupported model format version %d", decoded.Version)
+	}
+	if decoded.Network == nil {
+		return errors.New("pipeline has no network")
+	}
+
+	steps := make([]Transformer, len(decoded.Steps))
+	for i, step := range decoded.Steps {
+		transformer, err := UnmarshalTransformer(step)
+		if err != nil {
+			return err
+		}
+		steps[i] = transformer
+	}
+
+	var encoders map[string]CategoricalEncoder
+	if decoded.Encoders != nil {
+		encoders = make(map[string]CategoricalEncoder, len(decoded.Encoders))
+		for column, data := range decoded.Encoders {
+			encoder, err := UnmarshalEncoder(data)
+			if err != nil {
+				return fmt.Errorf("encoder of column %q: %w", column, err)
+			}
+			encoders[column] = encoder
+		}
+	}
+
+	if p.network == nil {
+		p.network = &Network{}
+	}
+	if err := p.network.UnmarshalJSON(decoded.Network); err != nil {
+		return err
+	}
+	p.steps = steps
+	p.encoders = encoders
+	p.labels = decoded.Labels
+	return nil
+}
+
+// Loads a Pipeline saved by SaveModel.
+func LoadPipeline(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := &Pipeline{}
+	if err := pipeline.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
@@ -0,0 +1,217 @@
+package feedforward
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default upper bounds of the prediction latency histogram buckets, in seconds.
+var defaultLatencyBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// Type holding the options of a PrometheusExporter.
+// Validation is the name of a metric of the received statistics which is reported as the validation loss, such as a
+// loss added to the network by AddMetric along with the validation samples.
+// Namespace prefixes the names of all metrics and defaults to "feedforward".
+// LatencyBuckets are the upper bounds of the prediction latency histogram buckets in seconds.
+type PrometheusOptions struct {
+	Validation     string
+	Namespace      string
+	LatencyBuckets []float64
+}
+
+// Observer type which exposes training progress and inference statistics as Prometheus metrics.
+// The exporter implements http.Handler, serving the metrics in the Prometheus text exposition format, so it can be
+// registered on a server under a path such as /metrics. Predictions are tracked for models wrapped by Instrument.
+type PrometheusExporter struct {
+	options PrometheusOptions
+	mutex   sync.Mutex
+
+	iteration       float64
+	loss            float64
+	validationLoss  float64
+	throughput      float64
+	learningRate    float64
+	iterationsTotal float64
+	samplesTotal    float64
+
+	lastUpdate    time.Time
+	lastSamples   int
+	lastIteration int
+
+	predictions      float64
+	predictionErrors float64
+	latencyCounts    []uint64
+	latencySum       float64
+}
+
+// Constructor for generating a new PrometheusExporter.
+func NewPrometheusExporter(options PrometheusOptions) *PrometheusExporter {
+	if options.Namespace == "" {
+		options.Namespace = "feedforward"
+	}
+	if options.LatencyBuckets == nil {
+		options.LatencyBuckets = defaultLatencyBuckets
+	}
+	return &PrometheusExporter{
+		options:        options,
+		loss:           math.NaN(),
+		validationLoss: math.NaN(),
+		learningRate:   math.NaN(),
+		latencyCounts:  make([]uint64, len(options.LatencyBuckets)),
+	}
+}
+
+// Records the given statistic.
+// The learning rate and the training throughput are reported for statistics implementing ProgressStatistic.
+// Scores are computed when the statistic is received, so that scraping never blocks on a full pass over the samples.
+func (e *PrometheusExporter) Update(statistic IterationStatistic) {
+	loss := statistic.GetScore()
+	validationLoss, learningRate := math.NaN(), math.NaN()
+	trained := 0
+	if metrics, ok := statistic.(MetricStatistic); ok && e.options.Validation != "" {
+		validationLoss, _ = metrics.GetMetric(e.options.Validation)
+	}
+	if progress, ok := statistic.(ProgressStatistic); ok {
+		learningRate = progress.GetLearningRate()
+		trained = progress.GetTrainedSamples()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// observers can be wrapped so that they receive only some iterations, so the iterations completed since the
+	// previous update are counted, an iteration lower than the previous one meaning that a new Fit has started
+	now := time.Now()
+	iteration := statistic.GetIteration()
+	if iteration > 0 {
+		completed := iteration - e.lastIteration
+		if completed < 0 {
+			completed = iteration
+		}
+		e.iterationsTotal += float64(completed)
+		if elapsed := now.Sub(e.lastUpdate).Seconds(); elapsed > 0 && trained >= e.lastSamples {
+			e.throughput = float64(trained-e.lastSamples) / elapsed
+			e.samplesTotal += float64(trained - e.lastSamples)
+		}
+	}
+	e.lastUpdate = now
+	e.lastSamples = trained
+	e.lastIteration = iteration
+
+	e.iteration = float64(iteration)
+	e.loss = loss
+	e.validationLoss = validationLoss
+	e.learningRate = learningRate
+}
+
+// Records a single prediction.
+func (e *PrometheusExporter) observePrediction(duration time.Duration, err error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.predictions++
+	if err != nil {
+		e.predictionErrors++
+	}
+	seconds := duration.Seconds()
+	e.latencySum += seconds
+	for i, bound := range e.options.LatencyBuckets {
+		if seconds <= bound {
+			e.latencyCounts[i]++
+		}
+	}
+}
+
+// Type wrapping a model, recording every prediction in an exporter.
+type instrumentedModel struct {
+	Model
+	exporter *PrometheusExporter
+}
+
+// Returns a model which behaves like the given model, recording the count, errors and latency of every prediction
+// in the exporter.
+func (e *PrometheusExporter) Instrument(model Model) Model {
+	return &instrumentedModel{Model: model, exporter: e}
+}
+
+// Performs a prediction using the wrapped model and records it.
+func (m *instrumentedModel) Predict(input []float64) ([]float64, error) {
+	start := time.Now()
+	output, err := m.Model.Predict(input)
+	m.exporter.observePrediction(time.Since(start), err)
+	return output, err
+}
+
+// Serves the current metrics in the Prometheus text exposition format.
+func (e *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// Writes the current metrics in the Prometheus text exposition format.
+func (e *PrometheusExporter) WriteTo(w io.Writer) (int64, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	writer := &countingWriter{writer: w}
+	e.writeMetric(writer, "training_iteration", "gauge", "Current training iteration.", e.iteration)
+	e.writeMetric(writer, "training_loss", "gauge", "Loss on the training samples.", e.loss)
+	e.writeMetric(writer, "validation_loss", "gauge", "Loss on the validation samples.", e.validationLoss)
+	e.writeMetric(writer, "training_throughput_samples_per_second", "gauge", "Training throughput since the previous update.", e.throughput)
+	e.writeMetric(writer, "learning_rate", "gauge", "Learning rate of the network.", e.learningRate)
+	e.writeMetric(writer, "training_iterations_total", "counter", "Total number of completed training iterations.", e.iterationsTotal)
+	e.writeMetric(writer, "training_samples_total", "counter", "Total number of samples trained on.", e.samplesTotal)
+	e.writeMetric(writer, "predictions_total", "counter", "Total number of predictions.", e.predictions)
+	e.writeMetric(writer, "prediction_errors_total", "counter", "Total number of failed predictions.", e.predictionErrors)
+
+	name := e.options.Namespace + "_prediction_duration_seconds"
+	writer.printf("# HELP %s Latency of predictions.\n# TYPE %s histogram\n", name, name)
+	for i, bound := range e.options.LatencyBuckets {
+		writer.printf("%s_bucket{le=\"%s\"} %d\n", name, formatValue(bound), e.latencyCounts[i])
+	}
+	writer.printf("%s_bucket{le=\"+Inf\"} %s\n", name, formatValue(e.predictions))
+	writer.printf("%s_sum %s\n%s_count %s\n", name, formatValue(e.latencySum), name, formatValue(e.predictions))
+	return writer.count, writer.err
+}
+
+// Writes a single sample metric with its help and type lines.
+func (e *PrometheusExporter) writeMetric(writer *countingWriter, name, kind, help string, value float64) {
+	name = e.options.Namespace + "_" + name
+	writer.printf("# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatValue(value))
+}
+
+// Formats a value as required by the Prometheus text exposition format.
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// Writer which counts written bytes and stops writing after the first error.
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+	err    error
+}
+
+// Formats and writes the given values.
+func (c *countingWriter) printf(format string, values ...interface{}) {
+	if c.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(c.writer, format, values...)
+	c.count += int64(n)
+	c.err = err
+}
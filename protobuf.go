@@ -0,0 +1,234 @@
+package feedforward
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protocol buffers wire types.
+const (
+	wireVarint  = int(protowire.VarintType)
+	wireFixed64 = int(protowire.Fixed64Type)
+	wireBytes   = int(protowire.BytesType)
+	wireFixed32 = int(protowire.Fixed32Type)
+)
+
+// Minimal protocol buffers encoder, sufficient for writing the messages of file formats such as TensorBoard event
+// files and ONNX models. Messages are written field by field using protowire, the wire format package of
+// google.golang.org/protobuf, rather than as generated message types, as generating them would require vendoring the
+// TensorBoard and ONNX schemas for the handful of fields this package uses.
+type protoWriter struct {
+	buffer []byte
+}
+
+// Appends a field key.
+func (p *protoWriter) key(field int, wireType int) {
+	p.buffer = protowire.AppendTag(p.buffer, protowire.Number(field), protowire.Type(wireType))
+}
+
+// Appends an integer field.
+func (p *protoWriter) int(field int, value int64) {
+	p.key(field, wireVarint)
+	p.buffer = protowire.AppendVarint(p.buffer, uint64(value))
+}
+
+// Appends a double field.
+func (p *protoWriter) double(field int, value float64) {
+	p.key(field, wireFixed64)
+	p.buffer = protowire.AppendFixed64(p.buffer, math.Float64bits(value))
+}
+
+// Appends a float field.
+func (p *protoWriter) float(field int, value float32) {
+	p.key(field, wireFixed32)
+	p.buffer = protowire.AppendFixed32(p.buffer, math.Float32bits(value))
+}
+
+// Appends a bytes field.
+func (p *protoWriter) bytes(field int, value []byte) {
+	p.key(field, wireBytes)
+	p.buffer = protowire.AppendBytes(p.buffer, value)
+}
+
+// Appends a string field.
+func (p *protoWriter) string(field int, value string) {
+	p.key(field, wireBytes)
+	p.buffer = protowire.AppendString(p.buffer, value)
+}
+
+// Appends an embedded message field.
+func (p *protoWriter) message(field int, message *protoWriter) {
+	p.bytes(field, message.buffer)
+}
+
+// Appends a packed repeated float field.
+func (p *protoWriter) packedFloats(field int, values []float32) {
+	packed := make([]byte, 0, 4*len(values))
+	for _, value := range values {
+		packed = protowire.AppendFixed32(packed, math.Float32bits(value))
+	}
+	p.bytes(field, packed)
+}
+
+// Appends a packed repeated double field.
+func (p *protoWriter) packedDoubles(field int, values []float64) {
+	packed := make([]byte, 0, 8*len(values))
+	for _, value := range values {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(value))
+	}
+	p.bytes(field, packed)
+}
+
+// Error returned when a packed repeated field does not hold a whole number of values.
+var errTruncatedMessage = errors.New("protobuf message is truncated")
+
+// Minimal protocol buffers decoder, the counterpart of protoWriter.
+// Fields are read in order by calling next followed by the read method matching the wire type of the field, or skip.
+type protoReader struct {
+	buffer []byte
+}
+
+// Checks whether there are fields left to read.
+func (p *protoReader) more() bool {
+	return len(p.buffer) > 0
+}
+
+// Advances past the given number of consumed bytes, or returns the error encoded by a negative number.
+func (p *protoReader) advance(n int) error {
+	if n < 0 {
+		return protowire.ParseError(n)
+	}
+	p.buffer = p.buffer[n:]
+	return nil
+}
+
+// Reads the key of the next field.
+func (p *protoReader) next() (field int, wireType int, err error) {
+	number, typ, n := protowire.ConsumeTag(p.buffer)
+	if err := p.advance(n); err != nil {
+		return 0, 0, err
+	}
+	return int(number), int(typ), nil
+}
+
+// Reads a varint.
+func (p *protoReader) varint() (uint64, error) {
+	value, n := protowire.ConsumeVarint(p.buffer)
+	return value, p.advance(n)
+}
+
+// Reads a fixed 64-bit value.
+func (p *protoReader) fixed64() (uint64, error) {
+	value, n := protowire.ConsumeFixed64(p.buffer)
+	return value, p.advance(n)
+}
+
+// Reads a fixed 32-bit value.
+func (p *protoReader) fixed32() (uint32, error) {
+	value, n := protowire.ConsumeFixed32(p.buffer)
+	return value, p.advance(n)
+}
+
+// Reads a length-delimited value, such as a string or an embedded message.
+func (p *protoReader) bytes() ([]byte, error) {
+	value, n := protowire.ConsumeBytes(p.buffer)
+	return value, p.advance(n)
+}
+
+// Skips the value of the given field.
+func (p *protoReader) skip(field int, wireType int) error {
+	return p.advance(protowire.ConsumeFieldValue(protowire.Number(field), protowire.Type(wireType), p.buffer))
+}
+
+// Reads the values of a repeated integer field, which may be packed or not.
+func (p *protoReader) ints(wireType int, values []int64) ([]int64, error) {
+	if wireType != wireBytes {
+		value, err := p.varint()
+		return append(values, int64(value)), err
+	}
+	packed, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	reader := &protoReader{buffer: packed}
+	for reader.more() {
+		value, err := reader.varint()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, int64(value))
+	}
+	return values, nil
+}
+
+// Reads the values of a repeated double field, which may be packed or not.
+func (p *protoReader) doubles(wireType int, values []float64) ([]float64, error) {
+	if wireType != wireBytes {
+		value, err := p.fixed64()
+		return append(values, math.Float64frombits(value)), err
+	}
+	packed, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(packed)%8 != 0 {
+		return nil, errTruncatedMessage
+	}
+	reader := &protoReader{buffer: packed}
+	for reader.more() {
+		value, err := reader.fixed64()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, math.Float64frombits(value))
+	}
+	return values, nil
+}
+
+// Reads the values of a repeated float field, which may be packed or not.
+func (p *protoReader) floats(wireType int, values []float32) ([]float32, error) {
+	if wireType != wireBytes {
+		value, err := p.fixed32()
+		return append(values, math.Float32frombits(value)), err
+	}
+	packed, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(packed)%4 != 0 {
+		return nil, errTruncatedMessage
+	}
+	reader := &protoReader{buffer: packed}
+	for reader.more() {
+		value, err := reader.fixed32()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, math.Float32frombits(value))
+	}
+	return values, nil
+}
+
+// Reads every field of the given message, calling read for every field. Fields for which read returns false are
+// skipped, read must consume the value of every other field.
+func decodeMessage(data []byte, read func(reader *protoReader, field int, wireType int) (bool, error)) error {
+	reader := &protoReader{buffer: data}
+	for reader.more() {
+		field, wireType, err := reader.next()
+		if err != nil {
+			return err
+		}
+		handled, err := read(reader, field, wireType)
+		if err != nil {
+			return err
+		}
+		if !handled {
+			if err := reader.skip(field, wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
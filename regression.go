@@ -0,0 +1,237 @@
+package feedforward
+
+import (
+	"math"
+	"sort"
+)
+
+// Represents a metric which is computed separately for every output of a predictor.
+// Per-output values can be reported directly or combined into a single Metric using Mean or Sum.
+type OutputMetric func(Predictor, []Sample) []float64
+
+// Returns a Metric which averages the per-output values uniformly.
+// Scores combined this way are comparable across models with a different number of outputs.
+func (m OutputMetric) Mean() Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		values := m(predictor, samples)
+		return sum(values) / float64(len(values))
+	}
+}
+
+// Returns a Metric which sums the per-output values.
+func (m OutputMetric) Sum() Metric {
+	return func(predictor Predictor, samples []Sample) float64 {
+		return sum(m(predictor, samples))
+	}
+}
+
+// Sums all values of the given slice.
+func sum(values []float64) float64 {
+	total := 0.
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// Computes the predictions for the given samples and arranges the expected and actual values per output, so that
+// expected[i][s] and actual[i][s] hold the i-th output of the s-th sample.
+func columns(predictor Predictor, samples []Sample) (expected, actual [][]float64) {
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	outputs := len(samples[0].Output)
+	expected = make([][]float64, outputs)
+	actual = make([][]float64, outputs)
+	for i := 0; i < outputs; i++ {
+		expected[i] = make([]float64, len(samples))
+		actual[i] = make([]float64, len(samples))
+	}
+
+	for s, sample := range samples {
+		prediction := predictor(sample.Input)
+		for i := 0; i < outputs; i++ {
+			expected[i][s] = sample.Output[i]
+			actual[i][s] = prediction[i]
+		}
+	}
+	return expected, actual
+}
+
+// Computes the given function over the expected and actual values of every output.
+func perOutput(predictor Predictor, samples []Sample, metric func(expected, actual []float64) float64) []float64 {
+	expected, actual := columns(predictor, samples)
+	values := make([]float64, len(expected))
+	for i := range expected {
+		values[i] = metric(expected[i], actual[i])
+	}
+	return values
+}
+
+// MSE of every output.
+func MeanSquareErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		mse := 0.
+		for s := range expected {
+			mse += math.Pow(expected[s]-actual[s], 2)
+		}
+		return mse / float64(len(expected))
+	})
+}
+
+// MSE loss function averaged over outputs.
+// Unlike MeanSquareError, which sums the squared errors of all outputs, the score does not grow with the number of
+// outputs.
+func UniformMeanSquareError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(MeanSquareErrorPerOutput).Mean()(predictor, samples)
+}
+
+// RMSE of every output.
+func RootMeanSquareErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	values := MeanSquareErrorPerOutput(predictor, samples)
+	for i := range values {
+		values[i] = math.Sqrt(values[i])
+	}
+	return values
+}
+
+// RMSE loss function averaged over outputs.
+func RootMeanSquareError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(RootMeanSquareErrorPerOutput).Mean()(predictor, samples)
+}
+
+// MAE of every output.
+func MeanAbsoluteErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		mae := 0.
+		for s := range expected {
+			mae += math.Abs(expected[s] - actual[s])
+		}
+		return mae / float64(len(expected))
+	})
+}
+
+// MAE loss function averaged over outputs.
+func MeanAbsoluteError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(MeanAbsoluteErrorPerOutput).Mean()(predictor, samples)
+}
+
+// MAPE of every output, expressed as a fraction rather than a percentage.
+// Expected values are bounded away from zero by the machine epsilon to avoid division by zero.
+func MeanAbsolutePercentageErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	const eps = 2.220446049250313e-16
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		mape := 0.
+		for s := range expected {
+			mape += math.Abs(expected[s]-actual[s]) / math.Max(math.Abs(expected[s]), eps)
+		}
+		return mape / float64(len(expected))
+	})
+}
+
+// MAPE loss function averaged over outputs.
+func MeanAbsolutePercentageError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(MeanAbsolutePercentageErrorPerOutput).Mean()(predictor, samples)
+}
+
+// Median absolute error of every output.
+func MedianAbsoluteErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		errors := make([]float64, len(expected))
+		for s := range expected {
+			errors[s] = math.Abs(expected[s] - actual[s])
+		}
+		return median(errors)
+	})
+}
+
+// Median absolute error loss function averaged over outputs.
+func MedianAbsoluteError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(MedianAbsoluteErrorPerOutput).Mean()(predictor, samples)
+}
+
+// Computes the median of the given values, sorting them in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// Maximum absolute error of every output.
+func MaxErrorPerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		maxError := 0.
+		for s := range expected {
+			maxError = math.Max(maxError, math.Abs(expected[s]-actual[s]))
+		}
+		return maxError
+	})
+}
+
+// Maximum absolute error loss function averaged over outputs.
+func MaxError(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(MaxErrorPerOutput).Mean()(predictor, samples)
+}
+
+// Coefficient of determination of every output.
+func R2PerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		mean := sum(expected) / float64(len(expected))
+		residual, total := 0., 0.
+		for s := range expected {
+			residual += math.Pow(expected[s]-actual[s], 2)
+			total += math.Pow(expected[s]-mean, 2)
+		}
+		return explained(residual, total)
+	})
+}
+
+// Coefficient of determination metric averaged over outputs (higher is better, 1 is a perfect fit).
+func R2(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(R2PerOutput).Mean()(predictor, samples)
+}
+
+// Explained variance of every output.
+func ExplainedVariancePerOutput(predictor Predictor, samples []Sample) []float64 {
+	return perOutput(predictor, samples, func(expected, actual []float64) float64 {
+		residuals := make([]float64, len(expected))
+		for s := range expected {
+			residuals[s] = expected[s] - actual[s]
+		}
+		return explained(variance(residuals), variance(expected))
+	})
+}
+
+// Explained variance metric averaged over outputs (higher is better, 1 is a perfect fit).
+func ExplainedVariance(predictor Predictor, samples []Sample) float64 {
+	return OutputMetric(ExplainedVariancePerOutput).Mean()(predictor, samples)
+}
+
+// Computes the population variance of the given values.
+func variance(values []float64) float64 {
+	mean := sum(values) / float64(len(values))
+	v := 0.
+	for _, value := range values {
+		v += math.Pow(value-mean, 2)
+	}
+	return v / float64(len(values))
+}
+
+// Computes 1 - unexplained/total, which is defined as 1 for a perfect fit of a constant target and 0 for an imperfect
+// fit of a constant target.
+func explained(unexplained, total float64) float64 {
+	if total == 0 {
+		if unexplained == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - unexplained/total
+}
@@ -2,6 +2,9 @@ package feedforward
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -22,47 +25,121 @@ type Delimiters struct {
 
 // Function for loading samples from a text file into a slice
 func Load(path string, delimiters Delimiters) ([]Sample, error) {
-	var samples []Sample
+	return ReadAll(NewFileSource(path, delimiters))
+}
+
+// Iterator which lazily parses samples from a text reader, one sample per line.
+type lineIterator struct {
+	scanner    *bufio.Scanner
+	closer     io.Closer
+	delimiters Delimiters
+	line       int
+}
+
+// Constructor of a lineIterator, closer may be nil if the underlying reader does not need to be closed.
+func newLineIterator(r io.Reader, closer io.Closer, delimiters Delimiters) SampleIterator {
+	return &lineIterator{scanner: bufio.NewScanner(r), closer: closer, delimiters: delimiters}
+}
+
+// Parses the next line into a sample.
+func (l *lineIterator) Next() (Sample, error) {
+	if !l.scanner.Scan() {
+		if err := l.scanner.Err(); err != nil {
+			return Sample{}, err
+		}
+		return Sample{}, io.EOF
+	}
+	l.line++
 
-	file, err := os.Open(path)
+	sample, err := parseSample(l.scanner.Text(), l.delimiters)
 	if err != nil {
-		panic(err)
+		return Sample{}, fmt.Errorf("line %d: %w", l.line, err)
 	}
+	return sample, nil
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if err := scanner.Err(); err != nil {
-			return nil, err
-		}
+// Closes the underlying reader if needed.
+func (l *lineIterator) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
 
-		rawSample := strings.Split(scanner.Text(), delimiters.InputOutput)
-		rawInput := strings.Split(rawSample[0], delimiters.InputValues)
-		rawOutput := strings.Split(rawSample[1], delimiters.OutputValues)
+// Parses a single line of text into a sample.
+func parseSample(line string, delimiters Delimiters) (Sample, error) {
+	rawSample := strings.Split(line, delimiters.InputOutput)
+	if len(rawSample) != 2 {
+		return Sample{}, errors.New("line does not contain exactly one input-output delimiter")
+	}
+	rawInput := strings.Split(rawSample[0], delimiters.InputValues)
+	rawOutput := strings.Split(rawSample[1], delimiters.OutputValues)
 
-		input := make([]float64, len(rawInput))
-		output := make([]float64, len(rawOutput))
+	input := make([]float64, len(rawInput))
+	output := make([]float64, len(rawOutput))
 
-		for i := 0; i < len(input); i++ {
-			val, err := strconv.ParseFloat(rawInput[i], 64)
-			if err != nil {
-				return nil, err
-			}
-			input[i] = val
+	for i := 0; i < len(input); i++ {
+		val, err := strconv.ParseFloat(rawInput[i], 64)
+		if err != nil {
+			return Sample{}, err
 		}
+		input[i] = val
+	}
 
-		for i := 0; i < len(output); i++ {
-			val, err := strconv.ParseFloat(rawOutput[i], 64)
-			if err != nil {
-				return nil, err
-			}
-			output[i] = val
+	for i := 0; i < len(output); i++ {
+		val, err := strconv.ParseFloat(rawOutput[i], 64)
+		if err != nil {
+			return Sample{}, err
 		}
-
-		samples = append(samples, Sample{Input: input, Output: output})
+		output[i] = val
 	}
-	if err := file.Close(); err != nil {
+
+	return Sample{Input: input, Output: output}, nil
+}
+
+// Source which opens the text file at the given path on every call to Open.
+type fileSource struct {
+	path       string
+	delimiters Delimiters
+}
+
+// Constructor of a DataSource which lazily reads samples from a text file in the format accepted by Load.
+// The file is reopened on every pass, so it is never loaded into memory as a whole.
+func NewFileSource(path string, delimiters Delimiters) DataSource {
+	return &fileSource{path: path, delimiters: delimiters}
+}
+
+// Opens the file and returns an iterator over its samples.
+func (f *fileSource) Open() (SampleIterator, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
 		return nil, err
 	}
+	return newLineIterator(file, file, f.delimiters), nil
+}
+
+// Source which reads samples from a reader in the format accepted by Load.
+type readerSource struct {
+	reader     io.Reader
+	delimiters Delimiters
+	opened     bool
+}
+
+// Constructor of a DataSource which lazily reads samples from the given reader in the format accepted by Load.
+// If the reader implements io.Seeker, it is rewound on every call to Open, otherwise it can only be opened once.
+func NewReaderSource(r io.Reader, delimiters Delimiters) DataSource {
+	return &readerSource{reader: r, delimiters: delimiters}
+}
 
-	return samples, nil
+// Rewinds the reader if possible and returns an iterator over its samples.
+func (r *readerSource) Open() (SampleIterator, error) {
+	if seeker, ok := r.reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	} else if r.opened {
+		return nil, errors.New("reader source can only be opened once, as the reader is not an io.Seeker")
+	}
+	r.opened = true
+	return newLineIterator(r.reader, nil, r.delimiters), nil
 }
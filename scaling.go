@@ -0,0 +1,337 @@
+package feedforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Represents a transformation of samples which is fitted to data before use, such as feature scaling.
+// Inputs are always transformed, outputs are transformed only if the transformer is configured to do so, in which case
+// InverseTransformOutput maps model outputs back to the original scale.
+// Transformers of this package can be encoded using encoding/json and restored using UnmarshalTransformer, so that
+// they can be stored alongside a model.
+// Fit returns an error if the samples cannot be fitted, such as samples of different dimensions.
+type Transformer interface {
+	Fit(samples []Sample) error
+	TransformInput(input []float64) ([]float64, error)
+	TransformOutput(output []float64) ([]float64, error)
+	InverseTransformOutput(output []float64) ([]float64, error)
+}
+
+// Returns transformed copies of the given samples, the given samples are not modified.
+func TransformSamples(transformer Transformer, samples []Sample) ([]Sample, error) {
+	transformed := make([]Sample, len(samples))
+	for i, sample := range samples {
+		input, err := transformer.TransformInput(sample.Input)
+		if err != nil {
+			return nil, err
+		}
+		output, err := transformer.TransformOutput(sample.Output)
+		if err != nil {
+			return nil, err
+		}
+		transformed[i] = Sample{Input: input, Output: output}
+	}
+	return transformed, nil
+}
+
+// Fits the transformer to the given samples and returns transformed copies of the samples.
+func FitTransform(transformer Transformer, samples []Sample) ([]Sample, error) {
+	if err := transformer.Fit(samples); err != nil {
+		return nil, err
+	}
+	return TransformSamples(transformer, samples)
+}
+
+// Extracts every feature of the given samples into a separate slice, either from inputs or from outputs.
+// Returns an error if the samples are not all of the same dimension.
+func features(samples []Sample, outputs bool) ([][]float64, error) {
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	vector := func(sample Sample) []float64 {
+		if outputs {
+			return sample.Output
+		}
+		return sample.Input
+	}
+
+	columns := make([][]float64, len(vector(samples[0])))
+	for i := range columns {
+		columns[i] = make([]float64, len(samples))
+	}
+	for s, sample := range samples {
+		if len(vector(sample)) != len(columns) {
+			return nil, fmt.Errorf("sample %d has %d features, expected %d", s, len(vector(sample)), len(columns))
+		}
+		for i, value := range vector(sample) {
+			columns[i][s] = value
+		}
+	}
+	return columns, nil
+}
+
+// Computes the q-th quantile of the given values using linear interpolation, sorting the values in place.
+func quantile(values []float64, q float64) float64 {
+	sort.Float64s(values)
+	position := q * float64(len(values)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return values[lower] + (values[upper]-values[lower])*(position-float64(lower))
+}
+
+// Type holding per-feature coefficients of an affine transformation x * Scale + Offset.
+type affine struct {
+	Scale  []float64 `json:"scale"`
+	Offset []float64 `json:"offset"`
+}
+
+// Applies the transformation to the given vector.
+func (a *affine) apply(values []float64) ([]float64, error) {
+	if len(values) != len(a.Scale) {
+		return nil, errors.New("given vector is not of expected dimension")
+	}
+	transformed := make([]float64, len(values))
+	for i, value := range values {
+		transformed[i] = value*a.Scale[i] + a.Offset[i]
+	}
+	return transformed, nil
+}
+
+// Applies the inverse transformation to the given vector.
+func (a *affine) invert(values []float64) ([]float64, error) {
+	if len(values) != len(a.Scale) {
+		return nil, errors.New("given vector is not of expected dimension")
+	}
+	original := make([]float64, len(values))
+	for i, value := range values {
+		original[i] = (value - a.Offset[i]) / a.Scale[i]
+	}
+	return original, nil
+}
+
+// Type implementing every scaler which maps each feature through an affine transformation.
+// The kind of the scaler determines how the coefficients are fitted.
+type affineScaler struct {
+	Kind         string    `json:"type"`
+	Range        []float64 `json:"range,omitempty"`
+	ScaleOutputs bool      `json:"scale_outputs"`
+	Input        *affine   `json:"input,omitempty"`
+	Output       *affine   `json:"output,omitempty"`
+}
+
+// Constructor of a scaler which standardizes features to zero mean and unit variance.
+func NewStandardScaler(scaleOutputs bool) Transformer {
+	return &affineScaler{Kind: "standard", ScaleOutputs: scaleOutputs}
+}
+
+// Constructor of a scaler which maps features linearly onto the interval [lower, upper].
+// Constant features are mapped onto lower.
+// Returns an error if lower is not smaller than upper, as no feature can be scaled onto such an interval.
+func NewMinMaxScaler(lower, upper float64, scaleOutputs bool) (Transformer, error) {
+	if !(lower < upper) {
+		return nil, fmt.Errorf("min-max scaling range [%v, %v] is empty", lower, upper)
+	}
+	return &affineScaler{Kind: "minmax", Range: []float64{lower, upper}, ScaleOutputs: scaleOutputs}, nil
+}
+
+// Constructor of a scaler which centers features on the median and scales them by the interquartile range,
+// which makes it robust to outliers.
+func NewRobustScaler(scaleOutputs bool) Transformer {
+	return &affineScaler{Kind: "robust", ScaleOutputs: scaleOutputs}
+}
+
+// Constructor of a scaler which divides features by their maximum absolute value, mapping them onto [-1, 1] without
+// shifting them.
+func NewMaxAbsScaler(scaleOutputs bool) Transformer {
+	return &affineScaler{Kind: "maxabs", ScaleOutputs: scaleOutputs}
+}
+
+// Fits the coefficients of the scaler to the given samples.
+// The scaler is left unchanged if the samples are not all of the same dimension.
+func (a *affineScaler) Fit(samples []Sample) error {
+	inputs, err := features(samples, false)
+	if err != nil {
+		return fmt.Errorf("inputs: %w", err)
+	}
+	var outputs [][]float64
+	if a.ScaleOutputs {
+		if outputs, err = features(samples, true); err != nil {
+			return fmt.Errorf("outputs: %w", err)
+		}
+	}
+
+	a.Input = a.fit(inputs)
+	a.Output = nil
+	if a.ScaleOutputs {
+		a.Output = a.fit(outputs)
+	}
+	return nil
+}
+
+// Fits the coefficients of every given feature.
+// Constant features, for which the scale cannot be computed, are only shifted.
+func (a *affineScaler) fit(columns [][]float64) *affine {
+	coefficients := &affine{Scale: make([]float64, len(columns)), Offset: make([]float64, len(columns))}
+	for i, column := range columns {
+		var center, spread float64
+		switch a.Kind {
+		case "standard":
+			center = sum(column) / float64(len(column))
+			spread = math.Sqrt(variance(column))
+		case "minmax":
+			lowest, highest := math.Inf(1), math.Inf(-1)
+			for _, value := range column {
+				lowest, highest = math.Min(lowest, value), math.Max(highest, value)
+			}
+			// the range of a constant feature is zero, in which case the feature is only shifted onto the lower bound
+			center, spread = lowest, highest-lowest
+			if spread != 0 {
+				spread /= a.Range[1] - a.Range[0]
+			}
+		case "robust":
+			center = quantile(column, 0.5)
+			spread = quantile(column, 0.75) - quantile(column, 0.25)
+		case "maxabs":
+			for _, value := range column {
+				spread = math.Max(spread, math.Abs(value))
+			}
+		}
+		if spread == 0 {
+			spread = 1
+		}
+		coefficients.Scale[i] = 1 / spread
+		coefficients.Offset[i] = -center / spread
+		if a.Kind == "minmax" {
+			coefficients.Offset[i] += a.Range[0]
+		}
+	}
+	return coefficients
+}
+
+// Transforms the given input.
+func (a *affineScaler) TransformInput(input []float64) ([]float64, error) {
+	if a.Input == nil {
+		return nil, errors.New("this instance of scaler has not been fitted yet")
+	}
+	return a.Input.apply(input)
+}
+
+// Transforms the given output if the scaler scales outputs, otherwise returns the output unchanged.
+func (a *affineScaler) TransformOutput(output []float64) ([]float64, error) {
+	if !a.ScaleOutputs {
+		return output, nil
+	}
+	if a.Output == nil {
+		return nil, errors.New("this instance of scaler has not been fitted yet")
+	}
+	return a.Output.apply(output)
+}
+
+// Maps the given output back to the original scale if the scaler scales outputs, otherwise returns the output
+// unchanged.
+func (a *affineScaler) InverseTransformOutput(output []float64) ([]float64, error) {
+	if !a.ScaleOutputs {
+		return output, nil
+	}
+	if a.Output == nil {
+		return nil, errors.New("this instance of scaler has not been fitted yet")
+	}
+	return a.Output.invert(output)
+}
+
+// Type implementing a log transformation of selected features.
+type logTransformer struct {
+	Kind    string `json:"type"`
+	Inputs  []int  `json:"inputs"`
+	Outputs []int  `json:"outputs"`
+}
+
+// Constructor of a transformer which maps the selected input and output features through log(1 + x).
+// Features must be greater than -1, smaller values are reported as an error. The transformer does not need to be
+// fitted, Fit is a no-op.
+func NewLogTransformer(inputs []int, outputs []int) Transformer {
+	return &logTransformer{Kind: "log", Inputs: inputs, Outputs: outputs}
+}
+
+// Does nothing, as the log transformation has no parameters.
+func (l *logTransformer) Fit(samples []Sample) error {
+	return nil
+}
+
+// Transforms the selected features of the given input.
+func (l *logTransformer) TransformInput(input []float64) ([]float64, error) {
+	return mapFeatures(input, l.Inputs, log1p)
+}
+
+// Transforms the selected features of the given output.
+func (l *logTransformer) TransformOutput(output []float64) ([]float64, error) {
+	return mapFeatures(output, l.Outputs, log1p)
+}
+
+// Computes log(1 + x), returning an error for values outside of its domain.
+func log1p(value float64) (float64, error) {
+	if !(value > -1) {
+		return 0, fmt.Errorf("log transformation requires values greater than -1, got %v", value)
+	}
+	return math.Log1p(value), nil
+}
+
+// Computes exp(x) - 1, which is defined for every value.
+func expm1(value float64) (float64, error) {
+	return math.Expm1(value), nil
+}
+
+// Maps the selected features of the given output back to the original scale.
+func (l *logTransformer) InverseTransformOutput(output []float64) ([]float64, error) {
+	return mapFeatures(output, l.Outputs, expm1)
+}
+
+// Returns a copy of the given vector with the function applied to the selected features.
+func mapFeatures(values []float64, selected []int, f func(float64) (float64, error)) ([]float64, error) {
+	mapped := append([]float64(nil), values...)
+	for _, i := range selected {
+		if i < 0 || i >= len(values) {
+			return nil, fmt.Errorf("feature index %d out of range for vector of dimension %d", i, len(values))
+		}
+		value, err := f(values[i])
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		mapped[i] = value
+	}
+	return mapped, nil
+}
+
+// Restores a transformer from JSON produced by marshalling a transformer of this package.
+func UnmarshalTransformer(data []byte) (Transformer, error) {
+	var header struct {
+		Kind string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	var transformer Transformer
+	switch header.Kind {
+	case "standard", "minmax", "robust", "maxabs":
+		transformer = &affineScaler{}
+	case "log":
+		transformer = &logTransformer{}
+	default:
+		return nil, fmt.Errorf("unknown transformer type %q", header.Kind)
+	}
+	if err := json.Unmarshal(data, transformer); err != nil {
+		return nil, err
+	}
+	if scaler, ok := transformer.(*affineScaler); ok && scaler.Kind == "minmax" {
+		if len(scaler.Range) != 2 || !(scaler.Range[0] < scaler.Range[1]) {
+			return nil, fmt.Errorf("invalid min-max scaling range %v", scaler.Range)
+		}
+	}
+	return transformer, nil
+}
@@ -0,0 +1,29 @@
+package feedforward
+
+import "math"
+
+// Represents a learning rate schedule which computes the learning rate used for the given Iteration from the initial
+// learning rate of the network.
+type Schedule func(eta float64, iteration int) float64
+
+// Returns a schedule which keeps the learning rate constant.
+func NewConstantSchedule() Schedule {
+	return func(eta float64, iteration int) float64 { return eta }
+}
+
+// Returns a schedule which multiplies the learning rate by factor every given number of Iterations.
+func NewStepDecay(every int, factor float64) Schedule {
+	return func(eta float64, iteration int) float64 {
+		return eta * math.Pow(factor, float64(iteration/every))
+	}
+}
+
+// Returns a schedule which decays the learning rate exponentially, eta * exp(-rate * iteration).
+func NewExponentialDecay(rate float64) Schedule {
+	return func(eta float64, iteration int) float64 { return eta * math.Exp(-rate*float64(iteration)) }
+}
+
+// Returns a schedule which decays the learning rate inversely proportional to time, eta / (1 + rate * iteration).
+func NewInverseTimeDecay(rate float64) Schedule {
+	return func(eta float64, iteration int) float64 { return eta / (1 + rate*float64(iteration)) }
+}
@@ -0,0 +1,478 @@
+package feedforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Type holding the values of hyperparameters of a single candidate, keyed by parameter name.
+type Params map[string]interface{}
+
+// Gets the parameter with the given name as a float64, converting integer values.
+// Panics if the parameter is not a number, as this indicates a mismatch between the space and the factory.
+func (p Params) Float(name string) float64 {
+	switch value := p[name].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	default:
+		panic(fmt.Sprintf("parameter %q is not a number", name))
+	}
+}
+
+// Gets the parameter with the given name as an int.
+// Panics if the parameter is not an int, as this indicates a mismatch between the space and the factory.
+func (p Params) Int(name string) int {
+	value, ok := p[name].(int)
+	if !ok {
+		panic(fmt.Sprintf("parameter %q is not an int", name))
+	}
+	return value
+}
+
+// Returns a human readable representation of the parameters, sorted by name.
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := p[name]
+		if activation, ok := value.(ActivationFunction); ok {
+			value = activation.Name
+		}
+		parts[i] = fmt.Sprintf("%s=%v", name, value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Represents a distribution of values of a single hyperparameter.
+// Discrete distributions additionally implement Values, which is required by grid search.
+type Distribution interface {
+	Sample(rng *rand.Rand) interface{}
+}
+
+// Represents a distribution with a finite set of values.
+type discrete interface {
+	Values() []interface{}
+}
+
+// Distribution choosing uniformly from the given values, which can be of any type, for example topologies or
+// activation functions.
+type Choice []interface{}
+
+// Samples one of the values uniformly.
+func (c Choice) Sample(rng *rand.Rand) interface{} {
+	return c[rng.Intn(len(c))]
+}
+
+// Gets all values of the distribution.
+func (c Choice) Values() []interface{} {
+	return c
+}
+
+// Distribution of integers in the closed interval [Low, High].
+type IntRange struct {
+	Low, High int
+}
+
+// Samples an integer uniformly.
+func (r IntRange) Sample(rng *rand.Rand) interface{} {
+	return r.Low + rng.Intn(r.High-r.Low+1)
+}
+
+// Gets all integers of the interval.
+func (r IntRange) Values() []interface{} {
+	values := make([]interface{}, 0, r.High-r.Low+1)
+	for i := r.Low; i <= r.High; i++ {
+		values = append(values, i)
+	}
+	return values
+}
+
+// Continuous uniform distribution on the interval [Low, High).
+type Uniform struct {
+	Low, High float64
+}
+
+// Samples a float64 uniformly.
+func (u Uniform) Sample(rng *rand.Rand) interface{} {
+	return u.Low + rng.Float64()*(u.High-u.Low)
+}
+
+// Continuous distribution whose logarithm is uniform on [log Low, log High), suited for parameters such as the
+// learning rate which span several orders of magnitude.
+type LogUniform struct {
+	Low, High float64
+}
+
+// Samples a float64 log-uniformly.
+func (l LogUniform) Sample(rng *rand.Rand) interface{} {
+	return math.Exp(math.Log(l.Low) + rng.Float64()*(math.Log(l.High)-math.Log(l.Low)))
+}
+
+// Represents the space of hyperparameters searched over, keyed by parameter name.
+type ParameterSpace map[string]Distribution
+
+// Enumerates every combination of the values of a discrete space, in a deterministic order.
+func (s ParameterSpace) grid() ([]Params, error) {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	candidates := []Params{{}}
+	for _, name := range names {
+		d, ok := s[name].(discrete)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q does not have a finite set of values, which grid search requires", name)
+		}
+		var expanded []Params
+		for _, candidate := range candidates {
+			for _, value := range d.Values() {
+				params := make(Params, len(candidate)+1)
+				for k, v := range candidate {
+					params[k] = v
+				}
+				params[name] = value
+				expanded = append(expanded, params)
+			}
+		}
+		candidates = expanded
+	}
+	return candidates, nil
+}
+
+// Samples a random candidate from the space.
+func (s ParameterSpace) sample(rng *rand.Rand) Params {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make(Params, len(s))
+	for _, name := range names {
+		params[name] = s[name].Sample(rng)
+	}
+	return params
+}
+
+// Type holding the outcome of evaluating a single candidate.
+// Budget is the number of epochs the candidate was trained for, 0 if it was trained until the stopping condition of
+// the network was met.
+type Trial struct {
+	Rank   int
+	Params Params
+	Mean   float64
+	Std    float64
+	Budget int
+}
+
+// Type holding the trials of a search, ranked from best to worst.
+type SearchResult struct {
+	Trials []Trial
+}
+
+// Gets the best trial of the search, ok is false if the search evaluated no trials.
+func (r *SearchResult) Best() (trial Trial, ok bool) {
+	if len(r.Trials) == 0 {
+		return Trial{}, false
+	}
+	return r.Trials[0], true
+}
+
+// Writes the ranked trials as an aligned text table.
+func (r *SearchResult) WriteTable(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "rank\tmean\tstd\tbudget\tparams")
+	for _, trial := range r.Trials {
+		fmt.Fprintf(table, "%d\t%.6g\t%.6g\t%d\t%s\n", trial.Rank, trial.Mean, trial.Std, trial.Budget, trial.Params)
+	}
+	return table.Flush()
+}
+
+// Type holding the configuration of a hyperparameter search.
+// Candidates are networks produced by Factory for parameters drawn from Space, scored by Metric using
+// cross-validation on the folds produced by Splitter, which defaults to a shuffled 3-fold split.
+// If Maximize is true, higher scores are better, otherwise lower scores are better.
+// Up to Parallelism candidates are evaluated concurrently, values smaller than 2 evaluate them sequentially.
+// If Rng is nil, a time-seeded rng is used.
+type Search struct {
+	Space       ParameterSpace
+	Factory     func(params Params) *Network
+	Metric      Metric
+	Maximize    bool
+	Splitter    Splitter
+	Parallelism int
+	Rng         *rand.Rand
+}
+
+// Validates the configuration of the search and fills in defaults.
+func (s *Search) prepare() error {
+	if s.Factory == nil || s.Metric == nil {
+		return errors.New("search requires a factory and a metric")
+	}
+	s.Rng = orNewRand(s.Rng)
+	if s.Splitter == nil {
+		s.Splitter = NewKFold(3, true, s.Rng)
+	}
+	return nil
+}
+
+// Evaluates every combination of the parameter values, which requires every distribution of the space to be
+// discrete. Networks are trained until their stopping condition is met.
+func (s *Search) Grid(ctx context.Context, samples []Sample) (*SearchResult, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	candidates, err := s.Space.grid()
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, samples, candidates)
+}
+
+// Evaluates the given number of candidates sampled randomly from the parameter space.
+// Networks are trained until their stopping condition is met.
+func (s *Search) Random(ctx context.Context, samples []Sample, trials int) (*SearchResult, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	candidates := make([]Params, trials)
+	for i := range candidates {
+		candidates[i] = s.Space.sample(s.Rng)
+	}
+	return s.evaluate(ctx, samples, candidates)
+}
+
+// Splitter which always returns the same folds.
+type predefinedFolds []Fold
+
+// Returns the predefined folds.
+func (p predefinedFolds) Split(samples []Sample) ([]Fold, error) {
+	return p, nil
+}
+
+// Cross-validates every candidate, training the networks until their stopping condition is met.
+// Samples are split once, so that every candidate is evaluated on the same folds.
+func (s *Search) evaluate(ctx context.Context, samples []Sample, candidates []Params) (*SearchResult, error) {
+	folds, err := s.Splitter.Split(samples)
+	if err != nil {
+		return nil, err
+	}
+	trials := make([]Trial, len(candidates))
+	err = parallel(ctx, len(candidates), s.Parallelism, func(ctx context.Context, i int) error {
+		factory := func() Model { return s.Factory(candidates[i]) }
+		result, err := CrossValidate(ctx, factory, samples, predefinedFolds(folds), map[string]Metric{"score": s.Metric}, 1)
+		if err != nil {
+			return err
+		}
+		trials[i] = Trial{Params: candidates[i], Mean: result.Mean["score"], Std: result.Std["score"]}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.rank(trials), nil
+}
+
+// Evaluates the given number of random candidates using successive halving.
+// All candidates are first trained for minBudget epochs, after which only the best 1/factor of the candidates are
+// trained further, for factor times as many epochs in total. This is repeated until a single candidate remains or
+// the budget would exceed maxBudget. Training is resumed using PartialFit, so the stopping condition of the networks
+// is replaced by an epoch budget.
+// Trials are ranked by the budget they reached first and by score second.
+func (s *Search) SuccessiveHalving(ctx context.Context, samples []Sample, candidates, minBudget, maxBudget, factor int) (*SearchResult, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	if minBudget < 1 || factor < 2 {
+		return nil, errors.New("successive halving requires a positive minimum budget and a factor of at least 2")
+	}
+	trials, err := s.halving(ctx, samples, candidates, minBudget, maxBudget, factor)
+	if err != nil {
+		return nil, err
+	}
+	return s.rank(trials), nil
+}
+
+// Evaluates random candidates using Hyperband, which runs successive halving in several brackets trading off the
+// number of candidates against the initial budget, so that candidates which learn slowly are not eliminated too
+// early. The largest bracket starts with maxBudget/factor^k epochs for the largest k for which this is at least one.
+// Trials of all brackets are ranked by the budget they reached first and by score second.
+func (s *Search) Hyperband(ctx context.Context, samples []Sample, maxBudget, factor int) (*SearchResult, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	if maxBudget < 1 || factor < 2 {
+		return nil, errors.New("hyperband requires a positive maximum budget and a factor of at least 2")
+	}
+
+	brackets := 0
+	for power := factor; power <= maxBudget; power *= factor {
+		brackets++
+	}
+
+	var trials []Trial
+	for bracket := brackets; bracket >= 0; bracket-- {
+		power := int(math.Pow(float64(factor), float64(bracket)))
+		candidates := int(math.Ceil(float64(brackets+1) / float64(bracket+1) * float64(power)))
+		bracketTrials, err := s.halving(ctx, samples, candidates, maxBudget/power, maxBudget, factor)
+		if err != nil {
+			return nil, err
+		}
+		trials = append(trials, bracketTrials...)
+	}
+	return s.rank(trials), nil
+}
+
+// Runs successive halving on random candidates and returns a trial for every candidate, holding the score at the
+// largest budget the candidate reached.
+func (s *Search) halving(ctx context.Context, samples []Sample, count, minBudget, maxBudget, factor int) ([]Trial, error) {
+	folds, err := s.Splitter.Split(samples)
+	if err != nil {
+		return nil, err
+	}
+	if len(folds) == 0 {
+		return nil, errors.New("splitter produced no folds")
+	}
+
+	type candidate struct {
+		trial    Trial
+		networks []*Network
+	}
+	candidates := make([]*candidate, count)
+	for i := range candidates {
+		params := s.Space.sample(s.Rng)
+		candidates[i] = &candidate{trial: Trial{Params: params}, networks: make([]*Network, len(folds))}
+	}
+
+	alive := candidates
+	trained := 0
+	for budget := minBudget; len(alive) > 0 && budget <= maxBudget; budget *= factor {
+		epochs := budget - trained
+		err := parallel(ctx, len(alive), s.Parallelism, func(ctx context.Context, i int) error {
+			c := alive[i]
+			scores := make([]float64, len(folds))
+			for f, fold := range folds {
+				if c.networks[f] == nil {
+					c.networks[f] = s.Factory(c.trial.Params)
+				}
+				network := c.networks[f]
+				network.SetStoppingCondition(NewMaxIter(epochs))
+				if err := network.PartialFitContext(ctx, selectSamples(samples, fold.Train)); err != nil {
+					return err
+				}
+				scores[f] = s.Metric(network.Predictor(), selectSamples(samples, fold.Test))
+			}
+			c.trial.Mean = sum(scores) / float64(len(scores))
+			c.trial.Std = math.Sqrt(variance(scores))
+			c.trial.Budget = budget
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		trained = budget
+
+		if len(alive) == 1 {
+			break
+		}
+		sort.SliceStable(alive, func(i, j int) bool { return s.better(alive[i].trial, alive[j].trial) })
+		keep := len(alive) / factor
+		if keep < 1 {
+			keep = 1
+		}
+		alive = alive[:keep]
+	}
+
+	trials := make([]Trial, len(candidates))
+	for i, c := range candidates {
+		trials[i] = c.trial
+	}
+	return trials, nil
+}
+
+// Checks whether the score of the first trial is better than the score of the second, NaN scores are the worst.
+func (s *Search) better(first, second Trial) bool {
+	if math.IsNaN(second.Mean) {
+		return !math.IsNaN(first.Mean)
+	}
+	if s.Maximize {
+		return first.Mean > second.Mean
+	}
+	return first.Mean < second.Mean
+}
+
+// Sorts the trials by budget and score and assigns ranks.
+func (s *Search) rank(trials []Trial) *SearchResult {
+	sort.SliceStable(trials, func(i, j int) bool {
+		if trials[i].Budget != trials[j].Budget {
+			return trials[i].Budget > trials[j].Budget
+		}
+		return s.better(trials[i], trials[j])
+	})
+	for i := range trials {
+		trials[i].Rank = i + 1
+	}
+	return &SearchResult{Trials: trials}
+}
+
+// Runs f for every index in [0, n), running up to limit calls concurrently.
+// Calls receive a context derived from the given one, which is canceled as soon as a call fails, so that no further
+// calls are started and running calls can stop early.
+// Returns the first error returned by f, or ctx.Err() if the context is done before all calls are started.
+func parallel(ctx context.Context, n, limit int, f func(ctx context.Context, i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var first error
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		select {
+		case semaphore <- struct{}{}:
+		case <-derived.Done():
+		}
+		if derived.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := f(derived, i); err != nil {
+				// calls interrupted by the cancellation fail after the first error, which is the one reported
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,450 @@
+// Schema of the gRPC prediction service of feedforward models.
+// The Go messages and service stubs of the grpcserve package are generated from this schema by go generate.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: predictor.proto
+
+package grpcserve
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PredictRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Input         []float64              `protobuf:"fixed64,1,rep,packed,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictRequest) Reset() {
+	*x = PredictRequest{}
+	mi := &file_predictor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictRequest) ProtoMessage() {}
+
+func (x *PredictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictRequest.ProtoReflect.Descriptor instead.
+func (*PredictRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PredictRequest) GetInput() []float64 {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+type PredictResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Output        []float64              `protobuf:"fixed64,1,rep,packed,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictResponse) Reset() {
+	*x = PredictResponse{}
+	mi := &file_predictor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictResponse) ProtoMessage() {}
+
+func (x *PredictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictResponse.ProtoReflect.Descriptor instead.
+func (*PredictResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PredictResponse) GetOutput() []float64 {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type PredictBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inputs        []*PredictRequest      `protobuf:"bytes,1,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictBatchRequest) Reset() {
+	*x = PredictBatchRequest{}
+	mi := &file_predictor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictBatchRequest) ProtoMessage() {}
+
+func (x *PredictBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictBatchRequest.ProtoReflect.Descriptor instead.
+func (*PredictBatchRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PredictBatchRequest) GetInputs() []*PredictRequest {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+type PredictBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Outputs       []*PredictResponse     `protobuf:"bytes,1,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictBatchResponse) Reset() {
+	*x = PredictBatchResponse{}
+	mi := &file_predictor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictBatchResponse) ProtoMessage() {}
+
+func (x *PredictBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictBatchResponse.ProtoReflect.Descriptor instead.
+func (*PredictBatchResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PredictBatchResponse) GetOutputs() []*PredictResponse {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+type MetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataRequest) Reset() {
+	*x = MetadataRequest{}
+	mi := &file_predictor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataRequest) ProtoMessage() {}
+
+func (x *MetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataRequest.ProtoReflect.Descriptor instead.
+func (*MetadataRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{4}
+}
+
+type MetadataResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Kind             string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Inputs           int32                  `protobuf:"varint,2,opt,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs          int32                  `protobuf:"varint,3,opt,name=outputs,proto3" json:"outputs,omitempty"`
+	Neurons          []int32                `protobuf:"varint,4,rep,packed,name=neurons,proto3" json:"neurons,omitempty"`
+	Activations      []string               `protobuf:"bytes,5,rep,name=activations,proto3" json:"activations,omitempty"`
+	Parameters       int32                  `protobuf:"varint,6,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	FormatVersion    int32                  `protobuf:"varint,7,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	Revision         int32                  `protobuf:"varint,8,opt,name=revision,proto3" json:"revision,omitempty"`
+	Checksum         string                 `protobuf:"bytes,9,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	LoadedAtUnixNano int64                  `protobuf:"varint,10,opt,name=loaded_at_unix_nano,json=loadedAtUnixNano,proto3" json:"loaded_at_unix_nano,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MetadataResponse) Reset() {
+	*x = MetadataResponse{}
+	mi := &file_predictor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataResponse) ProtoMessage() {}
+
+func (x *MetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataResponse.ProtoReflect.Descriptor instead.
+func (*MetadataResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MetadataResponse) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *MetadataResponse) GetInputs() int32 {
+	if x != nil {
+		return x.Inputs
+	}
+	return 0
+}
+
+func (x *MetadataResponse) GetOutputs() int32 {
+	if x != nil {
+		return x.Outputs
+	}
+	return 0
+}
+
+func (x *MetadataResponse) GetNeurons() []int32 {
+	if x != nil {
+		return x.Neurons
+	}
+	return nil
+}
+
+func (x *MetadataResponse) GetActivations() []string {
+	if x != nil {
+		return x.Activations
+	}
+	return nil
+}
+
+func (x *MetadataResponse) GetParameters() int32 {
+	if x != nil {
+		return x.Parameters
+	}
+	return 0
+}
+
+func (x *MetadataResponse) GetFormatVersion() int32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *MetadataResponse) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *MetadataResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *MetadataResponse) GetLoadedAtUnixNano() int64 {
+	if x != nil {
+		return x.LoadedAtUnixNano
+	}
+	return 0
+}
+
+var File_predictor_proto protoreflect.FileDescriptor
+
+const file_predictor_proto_rawDesc = "" +
+	"\n" +
+	"\x0fpredictor.proto\x12\x0efeedforward.v1\"&\n" +
+	"\x0ePredictRequest\x12\x14\n" +
+	"\x05input\x18\x01 \x03(\x01R\x05input\")\n" +
+	"\x0fPredictResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x03(\x01R\x06output\"M\n" +
+	"\x13PredictBatchRequest\x126\n" +
+	"\x06inputs\x18\x01 \x03(\v2\x1e.feedforward.v1.PredictRequestR\x06inputs\"Q\n" +
+	"\x14PredictBatchResponse\x129\n" +
+	"\aoutputs\x18\x01 \x03(\v2\x1f.feedforward.v1.PredictResponseR\aoutputs\"\x11\n" +
+	"\x0fMetadataRequest\"\xc2\x02\n" +
+	"\x10MetadataResponse\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06inputs\x18\x02 \x01(\x05R\x06inputs\x12\x18\n" +
+	"\aoutputs\x18\x03 \x01(\x05R\aoutputs\x12\x18\n" +
+	"\aneurons\x18\x04 \x03(\x05R\aneurons\x12 \n" +
+	"\vactivations\x18\x05 \x03(\tR\vactivations\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\x06 \x01(\x05R\n" +
+	"parameters\x12%\n" +
+	"\x0eformat_version\x18\a \x01(\x05R\rformatVersion\x12\x1a\n" +
+	"\brevision\x18\b \x01(\x05R\brevision\x12\x1a\n" +
+	"\bchecksum\x18\t \x01(\tR\bchecksum\x12-\n" +
+	"\x13loaded_at_unix_nano\x18\n" +
+	" \x01(\x03R\x10loadedAtUnixNano2\xd7\x02\n" +
+	"\tPredictor\x12J\n" +
+	"\aPredict\x12\x1e.feedforward.v1.PredictRequest\x1a\x1f.feedforward.v1.PredictResponse\x12Y\n" +
+	"\fPredictBatch\x12#.feedforward.v1.PredictBatchRequest\x1a$.feedforward.v1.PredictBatchResponse\x12T\n" +
+	"\rPredictStream\x12\x1e.feedforward.v1.PredictRequest\x1a\x1f.feedforward.v1.PredictResponse(\x010\x01\x12M\n" +
+	"\bMetadata\x12\x1f.feedforward.v1.MetadataRequest\x1a .feedforward.v1.MetadataResponseB5Z3github.com/andrijadukic/feedforward/serve/grpcserveb\x06proto3"
+
+var (
+	file_predictor_proto_rawDescOnce sync.Once
+	file_predictor_proto_rawDescData []byte
+)
+
+func file_predictor_proto_rawDescGZIP() []byte {
+	file_predictor_proto_rawDescOnce.Do(func() {
+		file_predictor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)))
+	})
+	return file_predictor_proto_rawDescData
+}
+
+var file_predictor_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_predictor_proto_goTypes = []any{
+	(*PredictRequest)(nil),       // 0: feedforward.v1.PredictRequest
+	(*PredictResponse)(nil),      // 1: feedforward.v1.PredictResponse
+	(*PredictBatchRequest)(nil),  // 2: feedforward.v1.PredictBatchRequest
+	(*PredictBatchResponse)(nil), // 3: feedforward.v1.PredictBatchResponse
+	(*MetadataRequest)(nil),      // 4: feedforward.v1.MetadataRequest
+	(*MetadataResponse)(nil),     // 5: feedforward.v1.MetadataResponse
+}
+var file_predictor_proto_depIdxs = []int32{
+	0, // 0: feedforward.v1.PredictBatchRequest.inputs:type_name -> feedforward.v1.PredictRequest
+	1, // 1: feedforward.v1.PredictBatchResponse.outputs:type_name -> feedforward.v1.PredictResponse
+	0, // 2: feedforward.v1.Predictor.Predict:input_type -> feedforward.v1.PredictRequest
+	2, // 3: feedforward.v1.Predictor.PredictBatch:input_type -> feedforward.v1.PredictBatchRequest
+	0, // 4: feedforward.v1.Predictor.PredictStream:input_type -> feedforward.v1.PredictRequest
+	4, // 5: feedforward.v1.Predictor.Metadata:input_type -> feedforward.v1.MetadataRequest
+	1, // 6: feedforward.v1.Predictor.Predict:output_type -> feedforward.v1.PredictResponse
+	3, // 7: feedforward.v1.Predictor.PredictBatch:output_type -> feedforward.v1.PredictBatchResponse
+	1, // 8: feedforward.v1.Predictor.PredictStream:output_type -> feedforward.v1.PredictResponse
+	5, // 9: feedforward.v1.Predictor.Metadata:output_type -> feedforward.v1.MetadataResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_predictor_proto_init() }
+func file_predictor_proto_init() {
+	if File_predictor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_predictor_proto_goTypes,
+		DependencyIndexes: file_predictor_proto_depIdxs,
+		MessageInfos:      file_predictor_proto_msgTypes,
+	}.Build()
+	File_predictor_proto = out.File
+	file_predictor_proto_goTypes = nil
+	file_predictor_proto_depIdxs = nil
+}
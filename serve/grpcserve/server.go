@@ -0,0 +1,142 @@
+// Package grpcserve exposes saved feedforward models for inference over gRPC.
+//
+// The service is declared in predictor.proto, from which the messages and the client and server stubs of this package
+// are generated.
+package grpcserve
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative predictor.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/andrijadukic/feedforward/serve"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Type implementing the prediction service on top of a serve.Model, which can be reloaded while serving.
+type Server struct {
+	UnimplementedPredictorServer
+	model *serve.Model
+}
+
+// Constructor of a prediction service which serves the given model.
+func NewServer(model *serve.Model) *Server {
+	return &Server{model: model}
+}
+
+// Creates a gRPC server with the prediction service registered.
+// Other services can be registered on the returned server as well.
+func NewGRPCServer(model *serve.Model, options ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(options...)
+	RegisterPredictorServer(server, NewServer(model))
+	return server
+}
+
+// Listens on the given address and serves the prediction service until the context is done, after which the server
+// stops accepting connections and waits for calls in flight to complete.
+// Returns nil after a graceful shutdown, or the error which stopped the server.
+func ListenAndServe(ctx context.Context, model *serve.Model, addr string, options ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, NewGRPCServer(model, options...), listener)
+}
+
+// Serves the given server on the given listener until the context is done, behaving like ListenAndServe.
+func Serve(ctx context.Context, server *grpc.Server, listener net.Listener) error {
+	failed := make(chan error, 1)
+	go func() { failed <- server.Serve(listener) }()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	server.GracefulStop()
+	return <-failed
+}
+
+// Predicts the output of a single input.
+func (s *Server) Predict(ctx context.Context, request *PredictRequest) (*PredictResponse, error) {
+	output, err := s.model.Predict(request.GetInput())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &PredictResponse{Output: output}, nil
+}
+
+// Predicts the outputs of a batch of inputs using the same model.
+func (s *Server) PredictBatch(ctx context.Context, request *PredictBatchRequest) (*PredictBatchResponse, error) {
+	inputs := make([][]float64, len(request.GetInputs()))
+	for i, input := range request.GetInputs() {
+		inputs[i] = input.GetInput()
+	}
+	outputs, err := s.model.PredictBatch(inputs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &PredictBatchResponse{Outputs: make([]*PredictResponse, len(outputs))}
+	for i, output := range outputs {
+		response.Outputs[i] = &PredictResponse{Output: output}
+	}
+	return response, nil
+}
+
+// Predicts the output of every input received on the stream, in order, until the client closes the stream.
+func (s *Server) PredictStream(stream Predictor_PredictStreamServer) error {
+	for i := 0; ; i++ {
+		request, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		output, err := s.model.Predict(request.GetInput())
+		if err != nil {
+			return toStatus(fmt.Errorf("input %d: %w", i, err))
+		}
+		if err := stream.Send(&PredictResponse{Output: output}); err != nil {
+			return err
+		}
+	}
+}
+
+// Describes the model currently served.
+func (s *Server) Metadata(ctx context.Context, request *MetadataRequest) (*MetadataResponse, error) {
+	metadata := s.model.Metadata()
+	response := &MetadataResponse{
+		Kind:          metadata.Kind,
+		Inputs:        int32(metadata.Inputs),
+		Outputs:       int32(metadata.Outputs),
+		Activations:   metadata.Activations,
+		Parameters:    int32(metadata.Parameters),
+		FormatVersion: int32(metadata.FormatVersion),
+		Revision:      int32(metadata.Revision),
+		Checksum:      metadata.Checksum,
+	}
+	for _, neurons := range metadata.Neurons {
+		response.Neurons = append(response.Neurons, int32(neurons))
+	}
+	if !metadata.LoadedAt.IsZero() {
+		response.LoadedAtUnixNano = metadata.LoadedAt.UnixNano()
+	}
+	return response, nil
+}
+
+// Maps a prediction error onto a gRPC status, invalid inputs being the fault of the client.
+func toStatus(err error) error {
+	if errors.Is(err, serve.ErrDimension) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
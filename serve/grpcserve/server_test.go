@@ -0,0 +1,142 @@
+package grpcserve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrijadukic/feedforward"
+	"github.com/andrijadukic/feedforward/serve"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Serves a freshly trained model in-process over an in-memory connection and returns a client connected to it.
+func newHarness(t *testing.T) (PredictorClient, *serve.Model) {
+	t.Helper()
+
+	network := feedforward.NewNetwork([]int{2, 3, 1}, feedforward.Repeat(feedforward.Sigmoid(), 2),
+		feedforward.NewUniformInitializer(-1, 1), feedforward.NewMaxIter(10), 0.1)
+	network.Fit([]feedforward.Sample{
+		{Input: []float64{0, 0}, Output: []float64{0}},
+		{Input: []float64{0, 1}, Output: []float64{1}},
+		{Input: []float64{1, 0}, Output: []float64{1}},
+		{Input: []float64{1, 1}, Output: []float64{0}},
+	})
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := feedforward.SaveModel(path, network); err != nil {
+		t.Fatal(err)
+	}
+	model, err := serve.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(model)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewPredictorClient(conn), model
+}
+
+func TestPredict(t *testing.T) {
+	client, model := newHarness(t)
+
+	response, err := client.Predict(context.Background(), &PredictRequest{Input: []float64{0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := model.Predict([]float64{0, 1})
+	if len(response.GetOutput()) != 1 || response.GetOutput()[0] != expected[0] {
+		t.Errorf("got %v, expected %v", response.GetOutput(), expected)
+	}
+
+	_, err = client.Predict(context.Background(), &PredictRequest{Input: []float64{0}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v for an input of wrong dimension, expected InvalidArgument", err)
+	}
+}
+
+func TestPredictBatch(t *testing.T) {
+	client, _ := newHarness(t)
+
+	request := &PredictBatchRequest{Inputs: []*PredictRequest{{Input: []float64{0, 0}}, {Input: []float64{1, 1}}}}
+	response, err := client.PredictBatch(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.GetOutputs()) != 2 {
+		t.Fatalf("got %d outputs, expected 2", len(response.GetOutputs()))
+	}
+
+	request.Inputs = append(request.Inputs, &PredictRequest{Input: []float64{1}})
+	if _, err := client.PredictBatch(context.Background(), request); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v for a batch holding an input of wrong dimension, expected InvalidArgument", err)
+	}
+}
+
+func TestPredictStream(t *testing.T) {
+	client, model := newHarness(t)
+
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	stream, err := client.PredictStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		if err := stream.Send(&PredictRequest{Input: input}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; ; i++ {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if i != len(inputs) {
+				t.Errorf("got %d outputs, expected %d", i, len(inputs))
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := model.Predict(inputs[i])
+		if response.GetOutput()[0] != expected[0] {
+			t.Errorf("output %d is %v, expected %v", i, response.GetOutput(), expected)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	client, model := newHarness(t)
+
+	response, err := client.Metadata(context.Background(), &MetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := model.Metadata()
+	if response.GetInputs() != 2 || response.GetOutputs() != 1 || response.GetChecksum() != metadata.Checksum {
+		t.Errorf("got %v, expected metadata of %+v", response, metadata)
+	}
+	if response.GetLoadedAtUnixNano() != metadata.LoadedAt.UnixNano() {
+		t.Errorf("got load time %d, expected %d", response.GetLoadedAtUnixNano(), metadata.LoadedAt.UnixNano())
+	}
+}
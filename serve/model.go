@@ -0,0 +1,144 @@
+// Package serve exposes saved feedforward models for inference over HTTP.
+//
+// A Model holds a model loaded from disk which can be used for prediction concurrently and reloaded without
+// interrupting predictions in flight. A Server exposes a Model as a JSON API.
+package serve
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/andrijadukic/feedforward"
+)
+
+// Type holding the description of a loaded model.
+type Metadata struct {
+	Path          string    `json:"path"`
+	Kind          string    `json:"kind"`
+	Inputs        int       `json:"inputs"`
+	Outputs       int       `json:"outputs"`
+	Neurons       []int     `json:"neurons"`
+	Activations   []string  `json:"activations"`
+	Parameters    int       `json:"parameters"`
+	FormatVersion int       `json:"format_version"`
+	Revision      int       `json:"revision"`
+	Checksum      string    `json:"checksum"`
+	LoadedAt      time.Time `json:"loaded_at"`
+}
+
+// Type holding a model loaded from disk along with its description.
+// A loaded model is never modified, so it can be used concurrently.
+type loadedModel struct {
+	model    feedforward.Model
+	metadata Metadata
+}
+
+// Type holding a saved model which can be used for prediction concurrently and reloaded from disk.
+// Predictions use the model which was current when they started, so a reload never interrupts them.
+type Model struct {
+	path    string
+	mutex   sync.RWMutex
+	current *loadedModel
+}
+
+// Loads the Network or Pipeline saved at the given path.
+func Load(path string) (*Model, error) {
+	m := &Model{path: path}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Loads the model from disk again, replacing the current model.
+// If loading fails, the current model is kept and the error is returned.
+func (m *Model) Reload() error {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return err
+	}
+	model, err := feedforward.UnmarshalModel(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.path, err)
+	}
+
+	network, kind := model, "network"
+	if pipeline, ok := model.(*feedforward.Pipeline); ok {
+		network, kind = pipeline.Network(), "pipeline"
+	}
+	layers := network.(*feedforward.Network).Inspect()
+
+	checksum := sha256.Sum256(data)
+	metadata := Metadata{
+		Path:          m.path,
+		Kind:          kind,
+		Inputs:        len(layers[0].Weights),
+		Outputs:       len(layers[len(layers)-1].Biases),
+		Neurons:       []int{len(layers[0].Weights)},
+		FormatVersion: feedforward.FormatVersion,
+		Checksum:      hex.EncodeToString(checksum[:]),
+		LoadedAt:      time.Now(),
+	}
+	for _, layer := range layers {
+		metadata.Neurons = append(metadata.Neurons, len(layer.Biases))
+		metadata.Activations = append(metadata.Activations, layer.Activation)
+		metadata.Parameters += (len(layer.Weights) + 1) * len(layer.Biases)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.current != nil {
+		metadata.Revision = m.current.metadata.Revision + 1
+	}
+	m.current = &loadedModel{model: model, metadata: metadata}
+	return nil
+}
+
+// Gets the current model.
+func (m *Model) load() *loadedModel {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.current
+}
+
+// Gets the description of the current model.
+func (m *Model) Metadata() Metadata {
+	return m.load().metadata
+}
+
+// Performs a prediction using the current model.
+func (m *Model) Predict(input []float64) ([]float64, error) {
+	return predict(m.load(), input)
+}
+
+// Performs a prediction for every given input using the same model, even if the model is reloaded meanwhile.
+// If an input is invalid, no predictions are returned and the error names the index of the input.
+func (m *Model) PredictBatch(inputs [][]float64) ([][]float64, error) {
+	current := m.load()
+	outputs := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		output, err := predict(current, input)
+		if err != nil {
+			return nil, fmt.Errorf("inputs[%d]: %w", i, err)
+		}
+		outputs[i] = output
+	}
+	return outputs, nil
+}
+
+// Error returned for inputs which are not of the dimension expected by the model.
+var ErrDimension = errors.New("given input is not of expected dimension")
+
+// Performs a prediction using the given model, validating the input dimension up front so that every kind of model
+// reports it the same way.
+func predict(current *loadedModel, input []float64) ([]float64, error) {
+	if len(input) != current.metadata.Inputs {
+		return nil, ErrDimension
+	}
+	return current.model.Predict(input)
+}
@@ -0,0 +1,205 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Type holding the options of a Server.
+// MaxBodyBytes limits the size of request bodies and defaults to 10 MiB, MaxBatchSize limits the number of inputs of
+// a single request and is unlimited if 0. ShutdownTimeout bounds how long a graceful shutdown waits for requests in
+// flight and defaults to 10 seconds.
+type Options struct {
+	MaxBodyBytes    int64
+	MaxBatchSize    int
+	ShutdownTimeout time.Duration
+}
+
+// Type which exposes a Model as a JSON API.
+//
+//	POST /predict   {"input": [...]} or {"inputs": [[...], ...]}, answered by {"output": [...]} or {"outputs": [...]}
+//	GET  /healthz   {"status": "ok"}
+//	GET  /metadata  the Metadata of the current model
+//	POST /reload    reloads the model from disk and answers with the Metadata of the new model
+//
+// Errors are answered by {"error": "..."} with a 4xx status for invalid requests and a 5xx status otherwise.
+type Server struct {
+	model   *Model
+	options Options
+	mux     *http.ServeMux
+}
+
+// Constructor of a Server which serves the given model.
+func NewServer(model *Model, options Options) *Server {
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = 10 << 20
+	}
+	if options.ShutdownTimeout <= 0 {
+		options.ShutdownTimeout = 10 * time.Second
+	}
+	s := &Server{model: model, options: options, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/predict", s.handlePredict)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	s.mux.HandleFunc("/metadata", s.handleMetadata)
+	s.mux.HandleFunc("/reload", s.handleReload)
+	return s
+}
+
+// Serves the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Listens on the given address and serves the API until the context is done, after which the server stops accepting
+// connections and waits for requests in flight to complete.
+// Returns nil after a graceful shutdown, or the error which stopped the server.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serves the API on the given listener until the context is done, behaving like ListenAndServe.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: s}
+	failed := make(chan error, 1)
+	go func() { failed <- server.Serve(listener) }()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.options.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-failed; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Type holding the body of a prediction request, exactly one of the fields must be set.
+type predictRequest struct {
+	Input  []float64   `json:"input"`
+	Inputs [][]float64 `json:"inputs"`
+}
+
+// Predicts the output of a single input or of a batch of inputs.
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+
+	var request predictRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.options.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if (request.Input == nil) == (request.Inputs == nil) {
+		writeError(w, http.StatusBadRequest, errors.New("exactly one of input and inputs must be given"))
+		return
+	}
+
+	if request.Input != nil {
+		output, err := s.model.Predict(request.Input)
+		if err != nil {
+			writeError(w, predictionStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]float64{"output": output})
+		return
+	}
+
+	if s.options.MaxBatchSize > 0 && len(request.Inputs) > s.options.MaxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch of %d inputs exceeds the limit of %d", len(request.Inputs), s.options.MaxBatchSize))
+		return
+	}
+	outputs, err := s.model.PredictBatch(request.Inputs)
+	if err != nil {
+		writeError(w, predictionStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][][]float64{"outputs": outputs})
+}
+
+// Reports that the server is able to serve predictions.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Describes the current model.
+func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.model.Metadata())
+}
+
+// Reloads the model from disk.
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	if err := s.model.Reload(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.model.Metadata())
+}
+
+// Checks the method of the request, answering with an error if it is not the allowed one.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || method == http.MethodGet && r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed, use %s", r.Method, method))
+	return false
+}
+
+// Maps a prediction error onto a status code, invalid inputs being the fault of the client.
+func predictionStatus(err error) int {
+	if errors.Is(err, ErrDimension) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// Writes the given value as a JSON response.
+// Values which cannot be encoded, such as predictions holding NaN, are answered by an error.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// Writes the given error as a JSON response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,173 @@
+package feedforward
+
+import (
+	"io"
+	"math/rand"
+)
+
+// Represents a source of samples which can be read lazily, any number of times.
+// Every call to Open starts a new pass over the samples.
+type DataSource interface {
+	Open() (SampleIterator, error)
+}
+
+// Represents an iterator over samples.
+// Next returns io.EOF once all samples have been read.
+// Close releases any resources held by the iterator and must be called once the iterator is no longer used.
+type SampleIterator interface {
+	Next() (Sample, error)
+	Close() error
+}
+
+// Function type implementing DataSource, which allows ordinary functions to be used as data sources,
+// for example to generate samples on the fly.
+type SourceFunc func() (SampleIterator, error)
+
+// Calls the underlying function.
+func (f SourceFunc) Open() (SampleIterator, error) {
+	return f()
+}
+
+// Function type implementing SampleIterator, which allows ordinary functions to be used as iterators.
+// Close is a no-op.
+type IteratorFunc func() (Sample, error)
+
+// Calls the underlying function.
+func (f IteratorFunc) Next() (Sample, error) {
+	return f()
+}
+
+// Does nothing, as an IteratorFunc holds no resources.
+func (f IteratorFunc) Close() error {
+	return nil
+}
+
+// Reads all samples of the given source into a slice.
+func ReadAll(source DataSource) ([]Sample, error) {
+	iterator, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	var samples []Sample
+	for {
+		sample, err := iterator.Next()
+		if err == io.EOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+}
+
+// Reads up to size samples from the given iterator.
+// Returns io.EOF only if no samples were read.
+func nextBatch(iterator SampleIterator, size int) ([]Sample, error) {
+	batch := make([]Sample, 0, size)
+	for len(batch) < size {
+		sample, err := iterator.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, sample)
+	}
+	if len(batch) == 0 {
+		return nil, io.EOF
+	}
+	return batch, nil
+}
+
+// Source backed by an in-memory slice of samples.
+type sliceSource struct {
+	samples []Sample
+}
+
+// Constructor of a DataSource backed by the given slice, the slice is never modified.
+func NewSliceSource(samples []Sample) DataSource {
+	return &sliceSource{samples: samples}
+}
+
+// Returns an iterator over the samples in their original order.
+func (s *sliceSource) Open() (SampleIterator, error) {
+	return s.iterate(nil), nil
+}
+
+// Returns an iterator over the samples in a random order, drawn from the whole slice rather than a bounded buffer.
+func (s *sliceSource) shuffled(rng *rand.Rand) SampleIterator {
+	return s.iterate(rng.Perm(len(s.samples)))
+}
+
+// Returns an iterator over the samples in the order given by the permutation, or in their original order if the
+// permutation is nil.
+func (s *sliceSource) iterate(permutation []int) SampleIterator {
+	i := 0
+	return IteratorFunc(func() (Sample, error) {
+		if i >= len(s.samples) {
+			return Sample{}, io.EOF
+		}
+		index := i
+		if permutation != nil {
+			index = permutation[i]
+		}
+		i++
+		return s.samples[index], nil
+	})
+}
+
+// Iterator which shuffles the samples of an underlying iterator using a bounded buffer.
+// The buffer is first filled with samples, after which every sample read from the underlying iterator replaces a
+// randomly chosen sample of the buffer, which is returned to the caller.
+type shuffleIterator struct {
+	iterator SampleIterator
+	buffer   []Sample
+	size     int
+	rng      *rand.Rand
+	drained  bool
+}
+
+// Constructor of an iterator which shuffles the samples of the given iterator using a buffer of the given size.
+// Larger buffers give a more uniform shuffle at the cost of memory, a buffer at least as large as the number of
+// samples gives a uniform shuffle.
+func NewShuffleIterator(iterator SampleIterator, size int, rng *rand.Rand) SampleIterator {
+	if size < 1 {
+		size = 1
+	}
+	return &shuffleIterator{iterator: iterator, buffer: make([]Sample, 0, size), size: size, rng: rng}
+}
+
+// Returns a random sample from the buffer, refilling it from the underlying iterator.
+func (s *shuffleIterator) Next() (Sample, error) {
+	for !s.drained && len(s.buffer) < s.size {
+		sample, err := s.iterator.Next()
+		if err == io.EOF {
+			s.drained = true
+			break
+		}
+		if err != nil {
+			return Sample{}, err
+		}
+		s.buffer = append(s.buffer, sample)
+	}
+
+	if len(s.buffer) == 0 {
+		return Sample{}, io.EOF
+	}
+
+	i := s.rng.Intn(len(s.buffer))
+	sample := s.buffer[i]
+	last := len(s.buffer) - 1
+	s.buffer[i] = s.buffer[last]
+	s.buffer = s.buffer[:last]
+	return sample, nil
+}
+
+// Closes the underlying iterator.
+func (s *shuffleIterator) Close() error {
+	return s.iterator.Close()
+}
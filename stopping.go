@@ -1,33 +1,65 @@
 package feedforward
 
+import (
+	"math"
+	"time"
+)
+
 // Struct which models an iterative algorithms stopping condition.
 // It holds a single function which takes an IterationStatistics type and returns true if stop condition is met, false otherwise
 type StoppingCondition struct {
 	IsMet func(statistic IterationStatistic) bool
+	// creates a fresh IsMet function of a stateful condition, nil for stateless conditions
+	fresh func() func(statistic IterationStatistic) bool
+}
+
+// Constructs a stateful stopping condition from a function creating a fresh IsMet function with its own state.
+// Every Fit uses a fresh IsMet function, so the same condition can be shared by networks trained concurrently.
+func newStatefulCondition(fresh func() func(statistic IterationStatistic) bool) StoppingCondition {
+	return StoppingCondition{IsMet: fresh(), fresh: fresh}
+}
+
+// Gets an IsMet function which does not share state with any other Fit.
+func (c StoppingCondition) instance() func(statistic IterationStatistic) bool {
+	if c.fresh == nil {
+		return c.IsMet
+	}
+	return c.fresh()
 }
 
 // Method used for combining two stopping conditions into a new stopping condition which will only return true if both
-// of the underlying stopping conditions return true
+// of the underlying stopping conditions return true.
+// Both conditions are always evaluated so that stateful conditions observe every Iteration.
 func (c StoppingCondition) And(other StoppingCondition) StoppingCondition {
-	return StoppingCondition{
-		IsMet: func(statistic IterationStatistic) bool { return c.IsMet(statistic) && other.IsMet(statistic) },
-	}
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		first, second := c.instance(), other.instance()
+		return func(statistic IterationStatistic) bool {
+			isMet, otherIsMet := first(statistic), second(statistic)
+			return isMet && otherIsMet
+		}
+	})
 }
 
 // Method used for combining two stopping conditions into a new stopping condition which will return true if either
-// of the underlying stopping conditions return true
+// of the underlying stopping conditions return true.
+// Both conditions are always evaluated so that stateful conditions observe every Iteration.
 func (c StoppingCondition) Or(other StoppingCondition) StoppingCondition {
-	return StoppingCondition{
-		IsMet: func(statistic IterationStatistic) bool { return c.IsMet(statistic) || other.IsMet(statistic) },
-	}
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		first, second := c.instance(), other.instance()
+		return func(statistic IterationStatistic) bool {
+			isMet, otherIsMet := first(statistic), second(statistic)
+			return isMet || otherIsMet
+		}
+	})
 }
 
 // Method for inverting a stopping condition.
 // Returns a new stopping condition which will return true if original condition returns false and vice versa
 func (c StoppingCondition) Not() StoppingCondition {
-	return StoppingCondition{
-		IsMet: func(statistic IterationStatistic) bool { return !c.IsMet(statistic) },
-	}
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		isMet := c.instance()
+		return func(statistic IterationStatistic) bool { return !isMet(statistic) }
+	})
 }
 
 // Returns a new stopping condition which will return true when maximum Iteration count is reached
@@ -39,3 +71,93 @@ func NewMaxIter(maxIter int) StoppingCondition {
 func NewPrecision(precision float64) StoppingCondition {
 	return StoppingCondition{IsMet: func(statistic IterationStatistic) bool { return statistic.GetScore() <= precision }}
 }
+
+// Returns a new stopping condition which will return true when the given wall-clock duration has elapsed.
+// The clock starts when the condition is evaluated for the first Iteration of every Fit, so the same condition can be
+// reused across multiple calls to Fit and shared by networks trained concurrently.
+func NewMaxDuration(duration time.Duration) StoppingCondition {
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		var start time.Time
+		return func(statistic IterationStatistic) bool {
+			if start.IsZero() || statistic.GetIteration() == 0 {
+				start = time.Now()
+			}
+			return time.Since(start) >= duration
+		}
+	})
+}
+
+// Returns a new stopping condition which will return true when the score has not improved by more than minDelta over
+// the best score seen for patience consecutive Iterations.
+// Every Fit uses its own state of the condition, so the same condition can be reused across multiple calls to Fit and
+// shared by networks trained concurrently.
+func NewPlateau(patience int, minDelta float64) StoppingCondition {
+	return newPlateau(patience, func(best float64) float64 { return minDelta })
+}
+
+// Returns a new stopping condition which will return true when the score has not improved by more than a fraction
+// minRelativeDelta of the best score seen for patience consecutive Iterations.
+// Every Fit uses its own state of the condition, so the same condition can be reused across multiple calls to Fit and
+// shared by networks trained concurrently.
+func NewRelativePlateau(patience int, minRelativeDelta float64) StoppingCondition {
+	return newPlateau(patience, func(best float64) float64 { return minRelativeDelta * math.Abs(best) })
+}
+
+// Returns a plateau stopping condition which uses the given function to compute the required improvement from the
+// best score seen.
+func newPlateau(patience int, delta func(best float64) float64) StoppingCondition {
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		best, wait := math.Inf(1), 0
+		return func(statistic IterationStatistic) bool {
+			if statistic.GetIteration() == 0 {
+				best, wait = math.Inf(1), 0
+			}
+			score := statistic.GetScore()
+			if math.IsInf(best, 1) || score < best-delta(best) {
+				best, wait = score, 0
+				return false
+			}
+			wait++
+			return wait >= patience
+		}
+	})
+}
+
+// Returns a new stopping condition which will return true when the score diverges, that is when the score is NaN or
+// infinite or when it has increased for the given number of consecutive Iterations.
+// Every Fit uses its own state of the condition, so the same condition can be reused across multiple calls to Fit and
+// shared by networks trained concurrently.
+func NewDivergence(consecutive int) StoppingCondition {
+	return newStatefulCondition(func() func(statistic IterationStatistic) bool {
+		previous, increases := math.NaN(), 0
+		return func(statistic IterationStatistic) bool {
+			if statistic.GetIteration() == 0 {
+				previous, increases = math.NaN(), 0
+			}
+			score := statistic.GetScore()
+			if math.IsNaN(score) || math.IsInf(score, 0) {
+				return true
+			}
+			if score > previous {
+				increases++
+			} else {
+				increases = 0
+			}
+			previous = score
+			return increases >= consecutive
+		}
+	})
+}
+
+// Returns a new stopping condition which will return true when the norm of the mean gradient over the previous
+// Iteration falls below the given threshold. The condition is never met while the gradient norm is math.NaN, such as
+// before the first epoch or when training with a learning rate of 0.
+func NewGradientNorm(threshold float64) StoppingCondition {
+	return StoppingCondition{IsMet: func(statistic IterationStatistic) bool { return statistic.GetGradientNorm() < threshold }}
+}
+
+// Returns a new stopping condition which will return true when the norm of the parameter update made in the previous
+// Iteration falls below the given threshold.
+func NewUpdateNorm(threshold float64) StoppingCondition {
+	return StoppingCondition{IsMet: func(statistic IterationStatistic) bool { return statistic.GetUpdateNorm() < threshold }}
+}
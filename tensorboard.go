@@ -0,0 +1,223 @@
+package feedforward
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Number of buckets of histograms written to TensorBoard.
+const histogramBuckets = 30
+
+// Type holding the options of a TensorBoardWriter.
+// Validation is the name of a metric of the received statistics which is written as the validation loss, such as a
+// loss added to the network by AddMetric along with the validation samples.
+// Histograms of the weights, biases and mean gradients of every layer are written every HistogramEvery iterations
+// for statistics implementing InspectionStatistic. HistogramEvery defaults to 1.
+type TensorBoardOptions struct {
+	Validation     string
+	HistogramEvery int
+}
+
+// Observer type which writes training progress to a TensorBoard event file, readable by TensorBoard pointed at the
+// log directory or any of its parents.
+// Write errors stop the writer, the first error can be retrieved using Err.
+type TensorBoardWriter struct {
+	options TensorBoardOptions
+	file    *os.File
+	writer  *bufio.Writer
+	err     error
+}
+
+// Constructor for generating a new TensorBoardWriter which creates a new event file in the given directory,
+// creating the directory if needed.
+func NewTensorBoardWriter(logDir string, options TensorBoardOptions) (*TensorBoardWriter, error) {
+	if options.HistogramEvery < 1 {
+		options.HistogramEvery = 1
+	}
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	now := time.Now()
+	name := fmt.Sprintf("events.out.tfevents.%d.%s.%d", now.Unix(), hostname, now.Nanosecond())
+	file, err := os.Create(filepath.Join(logDir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TensorBoardWriter{options: options, file: file, writer: bufio.NewWriter(file)}
+	version := &protoWriter{}
+	version.double(1, wallTime(now))
+	version.string(3, "brain.Event:2")
+	t.writeRecord(version.buffer)
+	if err := t.flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Gets the first error encountered while writing events, nil if there was none.
+func (t *TensorBoardWriter) Err() error {
+	return t.err
+}
+
+// Flushes buffered events and closes the event file.
+func (t *TensorBoardWriter) Close() error {
+	t.flush()
+	if err := t.file.Close(); t.err == nil {
+		t.err = err
+	}
+	return t.err
+}
+
+// Writes scalar summaries of the given statistic and, if configured, histograms of the parameters of its layers.
+// The learning rate is written for statistics implementing ProgressStatistic, the validation loss and metrics for
+// statistics implementing MetricStatistic.
+func (t *TensorBoardWriter) Update(statistic IterationStatistic) {
+	if t.err != nil {
+		return
+	}
+
+	summary := &protoWriter{}
+	scalar(summary, "loss/train", statistic.GetScore())
+	scalar(summary, "norm/gradient", statistic.GetGradientNorm())
+	scalar(summary, "norm/update", statistic.GetUpdateNorm())
+
+	if progress, ok := statistic.(ProgressStatistic); ok {
+		scalar(summary, "learning_rate", progress.GetLearningRate())
+	}
+	if metrics, ok := statistic.(MetricStatistic); ok {
+		values := metrics.GetMetrics()
+		if value, ok := values[t.options.Validation]; ok {
+			scalar(summary, "loss/validation", value)
+		}
+		for _, name := range metrics.MetricNames() {
+			scalar(summary, "metrics/"+name, values[name])
+		}
+	}
+
+	if inspection, ok := statistic.(InspectionStatistic); ok && statistic.GetIteration()%t.options.HistogramEvery == 0 {
+		for _, layer := range inspection.GetLayers() {
+			prefix := fmt.Sprintf("layer_%d/", layer.Layer)
+			histogram(summary, prefix+"weights", flatten(layer.Weights))
+			histogram(summary, prefix+"biases", layer.Biases)
+			histogram(summary, prefix+"weight_gradients", flatten(layer.WeightGradients))
+			histogram(summary, prefix+"bias_gradients", layer.BiasGradients)
+		}
+	}
+
+	event := &protoWriter{}
+	event.double(1, wallTime(time.Now()))
+	event.int(2, int64(statistic.GetIteration()))
+	event.message(5, summary)
+	t.writeRecord(event.buffer)
+	t.flush()
+}
+
+// Converts a time to seconds since the epoch.
+func wallTime(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+// Flattens a 2d slice.
+func flatten(values [][]float64) []float64 {
+	var flat []float64
+	for _, row := range values {
+		flat = append(flat, row...)
+	}
+	return flat
+}
+
+// Appends a scalar summary value, NaN values are skipped.
+func scalar(summary *protoWriter, tag string, value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+	v := &protoWriter{}
+	v.string(1, tag)
+	v.float(2, float32(value))
+	summary.message(1, v)
+}
+
+// Appends a histogram summary value of the given values, using equal width buckets.
+func histogram(summary *protoWriter, tag string, values []float64) {
+	if len(values) == 0 {
+		return
+	}
+
+	lowest, highest, sum, squares := math.Inf(1), math.Inf(-1), 0., 0.
+	for _, value := range values {
+		lowest, highest = math.Min(lowest, value), math.Max(highest, value)
+		sum += value
+		squares += value * value
+	}
+
+	limits := make([]float64, histogramBuckets)
+	counts := make([]float64, histogramBuckets)
+	width := (highest - lowest) / histogramBuckets
+	for i := range limits {
+		limits[i] = lowest + width*float64(i+1)
+	}
+	limits[histogramBuckets-1] = highest
+	for _, value := range values {
+		i := histogramBuckets - 1
+		if width > 0 {
+			i = int(math.Min(math.Floor((value-lowest)/width), histogramBuckets-1))
+		}
+		counts[i]++
+	}
+
+	histo := &protoWriter{}
+	histo.double(1, lowest)
+	histo.double(2, highest)
+	histo.double(3, float64(len(values)))
+	histo.double(4, sum)
+	histo.double(5, squares)
+	histo.packedDoubles(6, limits)
+	histo.packedDoubles(7, counts)
+
+	v := &protoWriter{}
+	v.string(1, tag)
+	v.message(5, histo)
+	summary.message(1, v)
+}
+
+// Castagnoli table used by the TFRecord checksums.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// Computes the masked CRC32C checksum used by the TFRecord format.
+func maskedChecksum(data []byte) uint32 {
+	checksum := crc32.Checksum(data, crc32c)
+	return ((checksum >> 15) | (checksum << 17)) + 0xa282ead8
+}
+
+// Writes the given data framed as a TFRecord.
+func (t *TensorBoardWriter) writeRecord(data []byte) {
+	if t.err != nil {
+		return
+	}
+	header := binary.LittleEndian.AppendUint64(nil, uint64(len(data)))
+	record := binary.LittleEndian.AppendUint32(header, maskedChecksum(header))
+	record = append(record, data...)
+	record = binary.LittleEndian.AppendUint32(record, maskedChecksum(data))
+	_, t.err = t.writer.Write(record)
+}
+
+// Flushes buffered events to the event file.
+func (t *TensorBoardWriter) flush() error {
+	if t.err == nil {
+		t.err = t.writer.Flush()
+	}
+	return t.err
+}
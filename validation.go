@@ -0,0 +1,298 @@
+package feedforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Type holding the indices of the training and test samples of a single fold.
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// Represents a strategy for splitting samples into folds for cross-validation.
+// Split returns an error if the samples cannot be split into folds which all hold training and test samples.
+type Splitter interface {
+	Split(samples []Sample) ([]Fold, error)
+}
+
+// Returns the given rng, or a new time-seeded one if the given rng is nil.
+func orNewRand(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return newRand()
+	}
+	return rng
+}
+
+// Selects the samples with the given indices.
+func selectSamples(samples []Sample, indices []int) []Sample {
+	selected := make([]Sample, len(indices))
+	for i, index := range indices {
+		selected[i] = samples[index]
+	}
+	return selected
+}
+
+// Groups sample indices by class, using the same conventions as the classification metrics.
+// Classes are returned in ascending order.
+func groupByClass(samples []Sample) [][]int {
+	groups := make(map[int][]int)
+	for i, sample := range samples {
+		class := classOf(sample.Output)
+		groups[class] = append(groups[class], i)
+	}
+
+	classes := make([]int, 0, len(groups))
+	for class := range groups {
+		classes = append(classes, class)
+	}
+	sort.Ints(classes)
+
+	grouped := make([][]int, len(classes))
+	for i, class := range classes {
+		grouped[i] = groups[class]
+	}
+	return grouped
+}
+
+// Splits the samples randomly into a training set and a test set holding the given fraction of samples.
+// If stratify is true, the split preserves the proportion of every class, using the same conventions as the
+// classification metrics. The given slice is not modified. If rng is nil, a time-seeded rng is used.
+// Returns an error if the test fraction is not within [0, 1].
+func TrainTestSplit(samples []Sample, testFraction float64, stratify bool, rng *rand.Rand) (train, test []Sample, err error) {
+	if !(testFraction >= 0 && testFraction <= 1) {
+		return nil, nil, fmt.Errorf("test fraction must be within [0, 1], got %v", testFraction)
+	}
+	rng = orNewRand(rng)
+
+	groups := [][]int{identity(len(samples))}
+	if stratify {
+		groups = groupByClass(samples)
+	}
+
+	for _, group := range groups {
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		testCount := int(math.Round(testFraction * float64(len(group))))
+		test = append(test, selectSamples(samples, group[:testCount])...)
+		train = append(train, selectSamples(samples, group[testCount:])...)
+	}
+	return train, test, nil
+}
+
+// Returns the slice [0, 1, ..., n-1].
+func identity(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// Builds folds from test index sets, using all other indices for training.
+func foldsFromTestSets(n int, testSets [][]int) []Fold {
+	folds := make([]Fold, len(testSets))
+	for f, test := range testSets {
+		isTest := make([]bool, n)
+		for _, i := range test {
+			isTest[i] = true
+		}
+		train := make([]int, 0, n-len(test))
+		for i := 0; i < n; i++ {
+			if !isTest[i] {
+				train = append(train, i)
+			}
+		}
+		sort.Ints(test)
+		folds[f] = Fold{Train: train, Test: test}
+	}
+	return folds
+}
+
+// Type holding the parameters of k-fold cross-validation.
+type kFold struct {
+	k          int
+	shuffle    bool
+	rng        *rand.Rand
+	stratified bool
+}
+
+// Constructor of a splitter which splits the samples into k folds of nearly equal size, every fold being used as
+// the test set once. k must be at least 2 and at most the number of samples, which Split reports as an error.
+// If shuffle is true, samples are shuffled using the given rng before splitting, if rng is nil a time-seeded rng is
+// used.
+func NewKFold(k int, shuffle bool, rng *rand.Rand) Splitter {
+	return &kFold{k: k, shuffle: shuffle, rng: orNewRand(rng)}
+}
+
+// Constructor of a splitter which behaves like NewKFold, but preserves the proportion of every class in every fold,
+// using the same conventions as the classification metrics.
+func NewStratifiedKFold(k int, shuffle bool, rng *rand.Rand) Splitter {
+	return &kFold{k: k, shuffle: shuffle, rng: orNewRand(rng), stratified: true}
+}
+
+// Splits the samples into k folds.
+// Samples of every group are dealt to the folds in turn, so that fold sizes differ by at most one sample per group.
+func (k *kFold) Split(samples []Sample) ([]Fold, error) {
+	if k.k < 2 || k.k > len(samples) {
+		return nil, fmt.Errorf("k-fold splitting requires 1 < k <= %d samples, got k = %d", len(samples), k.k)
+	}
+
+	groups := [][]int{identity(len(samples))}
+	if k.stratified {
+		groups = groupByClass(samples)
+	}
+
+	testSets := make([][]int, k.k)
+	next := 0
+	for _, group := range groups {
+		if k.shuffle {
+			k.rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		}
+		for _, index := range group {
+			testSets[next] = append(testSets[next], index)
+			next = (next + 1) % k.k
+		}
+	}
+	return foldsFromTestSets(len(samples), testSets), nil
+}
+
+// Type implementing leave-one-out cross-validation.
+type leaveOneOut struct{}
+
+// Constructor of a splitter which uses every sample as the test set once, training on all other samples.
+func NewLeaveOneOut() Splitter {
+	return leaveOneOut{}
+}
+
+// Splits the samples into as many folds as there are samples, which requires at least 2 samples.
+func (l leaveOneOut) Split(samples []Sample) ([]Fold, error) {
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("leave-one-out splitting requires at least 2 samples, got %d", len(samples))
+	}
+
+	testSets := make([][]int, len(samples))
+	for i := range samples {
+		testSets[i] = []int{i}
+	}
+	return foldsFromTestSets(len(samples), testSets), nil
+}
+
+// Type holding the scores of a model on a single fold.
+type FoldScore struct {
+	Fold    int
+	Scores  map[string]float64
+	FitTime time.Duration
+}
+
+// Type holding the results of cross-validation: the scores of every fold, and the mean and standard deviation of
+// every metric over all folds.
+type CrossValidationResult struct {
+	Folds []FoldScore
+	Mean  map[string]float64
+	Std   map[string]float64
+}
+
+// Represents a model which can be fitted with a context, such as a Network or a Pipeline.
+type contextModel interface {
+	FitContext(ctx context.Context, samples []Sample) error
+}
+
+// Cross-validates models produced by the factory on the folds produced by the splitter.
+// For every fold a fresh model is fitted on the training samples and scored on the test samples by every metric.
+// Up to parallelism folds are evaluated concurrently, values smaller than 2 evaluate folds sequentially.
+// The factory is called concurrently if folds are evaluated concurrently.
+// Models which implement FitContext, such as Network and Pipeline, are interrupted when the context is done.
+// Returns ctx.Err() if cross-validation was interrupted, or the first error produced by a model.
+func CrossValidate(ctx context.Context, factory func() Model, samples []Sample, splitter Splitter, metrics map[string]Metric, parallelism int) (*CrossValidationResult, error) {
+	folds, err := splitter.Split(samples)
+	if err != nil {
+		return nil, err
+	}
+	if len(folds) == 0 {
+		return nil, errors.New("splitter produced no folds")
+	}
+	result := &CrossValidationResult{Folds: make([]FoldScore, len(folds))}
+	err = parallel(ctx, len(folds), parallelism, func(ctx context.Context, f int) error {
+		score, err := evaluateFold(ctx, factory(), samples, folds[f], metrics)
+		score.Fold = f
+		result.Folds[f] = score
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Mean = make(map[string]float64, len(metrics))
+	result.Std = make(map[string]float64, len(metrics))
+	for name := range metrics {
+		values := make([]float64, len(folds))
+		for f := range result.Folds {
+			values[f] = result.Folds[f].Scores[name]
+		}
+		result.Mean[name] = sum(values) / float64(len(values))
+		result.Std[name] = math.Sqrt(variance(values))
+	}
+	return result, nil
+}
+
+// Fits the model on the training samples of the fold and scores it on the test samples of the fold.
+func evaluateFold(ctx context.Context, model Model, samples []Sample, fold Fold, metrics map[string]Metric) (FoldScore, error) {
+	if err := ctx.Err(); err != nil {
+		return FoldScore{}, err
+	}
+
+	train, test := selectSamples(samples, fold.Train), selectSamples(samples, fold.Test)
+
+	start := time.Now()
+	if fitter, ok := model.(contextModel); ok {
+		if err := fitter.FitContext(ctx, train); err != nil {
+			return FoldScore{}, err
+		}
+	} else {
+		model.Fit(train)
+	}
+	score := FoldScore{Scores: make(map[string]float64, len(metrics)), FitTime: time.Since(start)}
+
+	if len(test) == 0 {
+		return score, nil
+	}
+	predictor, failure := ModelPredictor(model, len(test[0].Output))
+	for name, metric := range metrics {
+		score.Scores[name] = metric(predictor, test)
+	}
+	return score, *failure
+}
+
+// Returns a Predictor which calls Predict of the given model, which enables scoring any Model using a LossFunction
+// or a Metric.
+// As a Predictor cannot return an error, a failed prediction is replaced by a vector of NaN values of the given
+// output dimension and the first error is stored in the returned error pointer, which should be checked after
+// scoring.
+func ModelPredictor(model Model, outputs int) (Predictor, *error) {
+	var failure error
+	var mutex sync.Mutex
+	predictor := func(input []float64) []float64 {
+		output, err := model.Predict(input)
+		if err != nil {
+			mutex.Lock()
+			if failure == nil {
+				failure = err
+			}
+			mutex.Unlock()
+
+			output = make([]float64, outputs)
+			for i := range output {
+				output[i] = math.NaN()
+			}
+		}
+		return output
+	}
+	return predictor, &failure
+}